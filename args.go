@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// argSpec declares a positional argument of a command.
+type argSpec struct {
+	name     string
+	optional bool
+	// keepCase leaves free text such as a nickname as it was typed.
+	// Everything else is lower-cased so names match PokeAPI's.
+	keepCase bool
+	// complete lists the values offered by tab completion.
+	complete func(*state) []string
+}
+
+// flagSpec declares a --flag option. Flags without a value are booleans.
+type flagSpec struct {
+	name        string
+	value       string
+	description string
+}
+
+type commandArgs struct {
+	positional []string
+	flags      map[string]string
+}
+
+// arg returns the i-th positional argument, or "" when it was not given.
+func (a commandArgs) arg(i int) string {
+	if i >= len(a.positional) {
+		return ""
+	}
+	return a.positional[i]
+}
+
+func (a commandArgs) flag(name string) string {
+	return a.flags[name]
+}
+
+func (a commandArgs) hasFlag(name string) bool {
+	_, ok := a.flags[name]
+	return ok
+}
+
+// tokenize splits a line into words. Single or double quotes group words
+// containing spaces. Words keep their case; parseArgs decides which ones
+// are lower-cased.
+func tokenize(text string) ([]string, error) {
+	tokens := []string{}
+	var current strings.Builder
+	inToken := false
+	var quote rune
+	for _, r := range text {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, errUnterminatedQuote
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// splitCommands splits a -c command line on the semicolons between
+// commands, leaving semicolons inside quotes to tokenize.
+func splitCommands(line string) []string {
+	commands := []string{}
+	var current strings.Builder
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == ';':
+			commands = append(commands, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(commands, current.String())
+}
+
+// parseArgs matches tokens against the command's declared arguments and
+// flags. Both "--flag value" and "--flag=value" are accepted, and "--"
+// ends flag parsing. Flags and their values are lower-cased, as are
+// positional arguments unless their spec keeps case.
+func parseArgs(command cliCommand, tokens []string) (commandArgs, error) {
+	parsed := commandArgs{positional: []string{}, flags: map[string]string{}}
+	flagsDone := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if flagsDone || !strings.HasPrefix(token, "--") {
+			if spec, ok := command.arg(len(parsed.positional)); !ok || !spec.keepCase {
+				token = strings.ToLower(token)
+			}
+			parsed.positional = append(parsed.positional, token)
+			continue
+		}
+		token = strings.ToLower(token)
+		if token == "--" {
+			flagsDone = true
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(token, "--"), "=")
+		spec, ok := command.flag(name)
+		if !ok {
+			return commandArgs{}, usageErrorf(command, "unknown flag --%s", name)
+		}
+		if spec.value == "" {
+			if hasValue {
+				return commandArgs{}, usageErrorf(command, "--%s does not take a value", name)
+			}
+		} else if !hasValue {
+			if i+1 >= len(tokens) {
+				return commandArgs{}, usageErrorf(command, "--%s needs a value", name)
+			}
+			i++
+			value = strings.ToLower(tokens[i])
+		}
+		parsed.flags[name] = value
+	}
+
+	required := 0
+	for _, spec := range command.args {
+		if !spec.optional {
+			required++
+		}
+	}
+	if len(parsed.positional) < required {
+		return commandArgs{}, usageErrorf(command, "missing %s", command.args[len(parsed.positional)].placeholder())
+	}
+	if len(parsed.positional) > len(command.args) {
+		return commandArgs{}, usageErrorf(command, "unexpected argument %q", parsed.positional[len(command.args)])
+	}
+	return parsed, nil
+}
+
+// arg returns the spec of the i-th positional argument.
+func (c cliCommand) arg(i int) (argSpec, bool) {
+	if i >= len(c.args) {
+		return argSpec{}, false
+	}
+	return c.args[i], true
+}
+
+func (c cliCommand) flag(name string) (flagSpec, bool) {
+	for _, spec := range c.flags {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+func (s argSpec) placeholder() string {
+	name := "<" + s.name + ">"
+	if s.optional {
+		name = "[" + name + "]"
+	}
+	return name
+}
+
+func (s flagSpec) usage() string {
+	if s.value == "" {
+		return "--" + s.name
+	}
+	return fmt.Sprintf("--%s <%s>", s.name, s.value)
+}
+
+// usage is the synopsis of a command generated from its declared
+// arguments, e.g. "catch <pokemon_name> [--ball <ball>]".
+func (c cliCommand) usage() string {
+	parts := []string{c.name}
+	for _, spec := range c.args {
+		parts = append(parts, spec.placeholder())
+	}
+	for _, spec := range c.flags {
+		parts = append(parts, "["+spec.usage()+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+type usageError struct {
+	problem string
+	usage   string
+}
+
+func (e usageError) Error() string {
+	return fmt.Sprintf("%s, usage: %s", e.problem, e.usage)
+}
+
+func usageErrorf(command cliCommand, format string, args ...any) error {
+	return usageError{problem: fmt.Sprintf(format, args...), usage: command.usage()}
+}
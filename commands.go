@@ -0,0 +1,865 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thmastin/pokedexcli/internal/nameindex"
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+	"github.com/thmastin/pokedexcli/internal/trainer"
+)
+
+var commands map[string]cliCommand
+
+// cliCommand describes a REPL command. Its usage line and argument
+// validation are generated from args and flags.
+type cliCommand struct {
+	name        string
+	description string
+	args        []argSpec
+	flags       []flagSpec
+	callback    func(*state, commandArgs) (result, error)
+}
+
+func commandExit(_ *state, _ commandArgs) (result, error) {
+	return messageResult{Message: "Closing the Pokedex... Goodbye!"}, errExit
+}
+
+func commandHelp(_ *state, args commandArgs) (result, error) {
+	if name := args.arg(0); name != "" {
+		command, exists := commands[name]
+		if !exists {
+			return nil, unknownCommand(name)
+		}
+		res := commandHelpResult{
+			Name:        command.name,
+			Usage:       command.usage(),
+			Description: command.description,
+			Flags:       []commandInfo{},
+		}
+		for _, spec := range command.flags {
+			res.Flags = append(res.Flags, commandInfo{Name: spec.name, Usage: spec.usage(), Description: spec.description})
+		}
+		return res, nil
+	}
+
+	res := helpResult{Commands: []commandInfo{}}
+	for _, value := range commands {
+		res.Commands = append(res.Commands, commandInfo{Name: value.name, Usage: value.usage(), Description: value.description})
+	}
+	sort.Slice(res.Commands, func(i, j int) bool {
+		return res.Commands[i].Name < res.Commands[j].Name
+	})
+	return res, nil
+}
+
+func commandSet(st *state, args commandArgs) (result, error) {
+	setting, value := args.arg(0), args.arg(1)
+	switch setting {
+	case "output":
+		if err := st.setOutputFormat(value); err != nil {
+			return nil, err
+		}
+	case "prefetch-encounters":
+		switch value {
+		case "on":
+			st.prefetchEncounters = true
+		case "off":
+			st.prefetchEncounters = false
+		default:
+			return nil, fmt.Errorf("prefetch-encounters must be on or off")
+		}
+	default:
+		return nil, fmt.Errorf("unknown setting: %s", setting)
+	}
+	return messageResult{Message: fmt.Sprintf("%s set to %s", setting, value)}, nil
+}
+
+func commandMap(st *state, args commandArgs) (result, error) {
+	return showPage(st, "areas", args.arg(0), args)
+}
+
+func commandMapb(st *state, args commandArgs) (result, error) {
+	return showPage(st, "areas", "prev", args)
+}
+
+func commandRegion(st *state, args commandArgs) (result, error) {
+	regionName := args.arg(0)
+	region, err := st.client.Region(regionName)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, regionName, st.regionCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := regionResult{Region: region.Name, Generation: region.MainGeneration.Name, Locations: []string{}}
+	for _, location := range region.Locations {
+		res.Locations = append(res.Locations, location.Name)
+		st.knownLocations[location.Name] = true
+	}
+	st.knownRegions[region.Name] = true
+	return res, nil
+}
+
+func commandLocation(st *state, args commandArgs) (result, error) {
+	locationName := args.arg(0)
+	location, err := st.client.Location(locationName)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, locationName, st.locationCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := locationResult{Location: location.Name, Region: location.Region.Name, Areas: []string{}}
+	for _, area := range location.Areas {
+		res.Areas = append(res.Areas, area.Name)
+		st.knownAreas[area.Name] = true
+	}
+	st.knownLocations[location.Name] = true
+	return res, nil
+}
+
+func commandExplore(st *state, args commandArgs) (result, error) {
+	areaName, version, sortBy := args.arg(0), args.flag("version"), args.flag("sort")
+	switch sortBy {
+	case "", "name":
+	case "chance":
+		if version == "" {
+			return nil, fmt.Errorf("--sort chance needs --version")
+		}
+	default:
+		return nil, fmt.Errorf("--sort must be chance or name")
+	}
+
+	encounter, err := st.client.Encounter(areaName)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, areaName, st.areaCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := processEncounterResponse(encounter, areaName)
+	if version != "" {
+		res, err = processEncounterDetails(encounter, areaName, version)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortEncounters(&res, sortBy)
+	st.knownAreas[areaName] = true
+	st.lastEncounter = res.Pokemon
+	return res, nil
+}
+
+func commandGoto(st *state, args commandArgs) (result, error) {
+	areaName := args.arg(0)
+	if areaName == "" {
+		if st.trainer.Location == "" {
+			return nil, trainer.ErrNoLocation
+		}
+		return messageResult{Message: fmt.Sprintf("You are in %s", st.trainer.Location)}, nil
+	}
+	encounter, err := st.client.Encounter(areaName)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, areaName, st.areaCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	st.trainer.Goto(trainer.NewArea(areaName, encounter))
+	st.knownAreas[areaName] = true
+	if err := st.writeSave(); err != nil {
+		st.warnf("your location could not be saved: %v", err)
+	}
+	return messageResult{Message: fmt.Sprintf("You are now in %s, %d species of Pokemon live here", areaName, len(st.trainer.Area.Slots))}, nil
+}
+
+func commandWalk(st *state, _ commandArgs) (result, error) {
+	if st.trainer.Location == "" {
+		return nil, trainer.ErrNoLocation
+	}
+	if err := st.loadArea(); err != nil {
+		return nil, err
+	}
+	slot, level, ok := st.trainer.Area.Roll(st.rng)
+	if !ok {
+		message := fmt.Sprintf("You walk around %s, but no wild Pokemon live here", st.trainer.Location)
+		if found := st.findBall(); found != "" {
+			message += fmt.Sprintf("\nYou found %s!", withArticle(found))
+		}
+		return messageResult{Message: message}, nil
+	}
+	pokemon, err := st.client.Pokemon(slot.Species)
+	if err != nil {
+		return nil, err
+	}
+	wild := trainer.NewWild(pokemon, level, st.rng)
+	st.trainer.Wild = &wild
+	res := wildOutput(wild, st.trainer.Location)
+	res.Found = st.findBall()
+	return res, nil
+}
+
+// findBall rolls for a ball lying around on a walk, so an empty bag can
+// always be restocked. It returns the ball's name, or "" if none was found.
+func (st *state) findBall() string {
+	ball, ok := st.trainer.Bag.FindBall(st.rng)
+	if !ok {
+		return ""
+	}
+	if err := st.writeSave(); err != nil {
+		st.warnf("your bag could not be saved: %v", err)
+	}
+	return ball.Display
+}
+
+func wildOutput(wild trainer.Wild, areaName string) wildResult {
+	res := wildResult{Pokemon: wild.Species, Level: wild.Level, Area: areaName, Stats: []statInfo{}}
+	for _, stat := range wild.Stats {
+		res.Stats = append(res.Stats, statInfo{Name: stat.Name, Value: stat.Value})
+	}
+	return res
+}
+
+func commandCatch(st *state, args commandArgs) (result, error) {
+	pokemonName := args.arg(0)
+	ball := trainer.Balls[0]
+	if args.hasFlag("ball") {
+		var err error
+		ball, err = trainer.ParseBall(args.flag("ball"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	wild, err := st.trainer.Target(pokemonName)
+	if errors.Is(err, trainer.ErrNotHere) {
+		return nil, didYouMean(err, pokemonName, []string{st.trainer.Wild.Species})
+	}
+	if err != nil {
+		return nil, err
+	}
+	pokemon, err := st.client.Pokemon(wild.Species)
+	if err != nil {
+		return nil, err
+	}
+	species, err := st.client.Species(trainer.SpeciesName(pokemon))
+	if err != nil {
+		return nil, err
+	}
+	return processCatchResponse(st, pokemon, species, wild, ball)
+}
+
+func commandWhere(st *state, args commandArgs) (result, error) {
+	pokemonName := args.arg(0)
+	encounters, err := st.client.PokemonEncounters(pokemonName)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, pokemonName, st.pokemonCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := processPokemonEncounters(encounters, pokemonName, args.flag("version"))
+	for _, row := range res.Encounters {
+		st.knownAreas[row.Area] = true
+	}
+	return res, nil
+}
+
+func commandInspect(st *state, args commandArgs) (result, error) {
+	pokemonName := args.arg(0)
+	entry, err := st.pc.Find(pokemonName)
+	if errors.Is(err, trainer.ErrNotCaught) {
+		return messageResult{Message: fmt.Sprintf("%v, you can try to catch it by using the 'catch' command", err)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := inspectOutput(entry.Pokemon)
+	addEntryDetails(&res, *entry)
+	if args.hasFlag("species") {
+		species, err := st.client.Species(entry.Species())
+		if err != nil {
+			return nil, err
+		}
+		speciesRes := speciesOutput(species, language(args))
+		res.Species = &speciesRes
+	}
+	return res, nil
+}
+
+// addEntryDetails adds what sets one caught Pokemon apart from others of
+// its kind to an inspect result.
+func addEntryDetails(res *inspectResult, entry trainer.Entry) {
+	res.ID = entry.ID
+	res.Nickname = entry.Nickname
+	res.Level = entry.Level
+	res.XP = entry.XP
+	res.Nature = entry.Nature
+	res.Gender = entry.Gender
+	res.Shiny = entry.Shiny
+	res.CaughtAt = entry.CaughtAt
+	res.CaughtIn = entry.CaughtIn
+	if len(entry.IVs) > 0 {
+		for _, stat := range entry.Stats() {
+			res.IVs = append(res.IVs, statInfo{Name: stat.Name, Value: stat.IV})
+			res.EVs = append(res.EVs, statInfo{Name: stat.Name, Value: entry.EVs[stat.Name]})
+		}
+	}
+	for _, event := range entry.History {
+		res.History = append(res.History, historyInfo{At: event.At, Description: event.Description})
+	}
+}
+
+func commandSpecies(st *state, args commandArgs) (result, error) {
+	name := args.arg(0)
+	species, err := st.client.Species(name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, name, st.pokemonCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return speciesOutput(species, language(args)), nil
+}
+
+// language is the language asked for with --lang.
+func language(args commandArgs) string {
+	if args.hasFlag("lang") {
+		return args.flag("lang")
+	}
+	return pokeapi.DefaultLanguage
+}
+
+func speciesOutput(species pokeapi.PokemonSpecies, lang string) speciesResult {
+	return speciesResult{
+		Name:          species.Name,
+		Genus:         species.Genus(lang),
+		CaptureRate:   species.CaptureRate,
+		BaseHappiness: species.BaseHappiness,
+		GrowthRate:    species.GrowthRate.Name,
+		Baby:          species.IsBaby,
+		Legendary:     species.IsLegendary,
+		Mythical:      species.IsMythical,
+		FlavorText:    species.FlavorText(lang),
+	}
+}
+
+func inspectOutput(pokemon pokeapi.Pokemon) inspectResult {
+	res := inspectResult{
+		Name:   pokemon.Name,
+		Height: pokemon.Height,
+		Weight: pokemon.Weight,
+		Stats:  []statInfo{},
+		Types:  []string{},
+	}
+	for _, v := range pokemon.Stats {
+		res.Stats = append(res.Stats, statInfo{Name: v.Stat.Name, Value: v.BaseStat})
+	}
+	for _, v := range pokemon.Types {
+		res.Types = append(res.Types, v.Type.Name)
+	}
+	return res
+}
+
+func commandSearch(st *state, args commandArgs) (result, error) {
+	kinds := nameindex.Kinds
+	if args.hasFlag("kind") {
+		kind, err := nameindex.ParseKind(args.flag("kind"))
+		if err != nil {
+			return nil, err
+		}
+		kinds = []nameindex.Kind{kind}
+	}
+	limit := 20
+	if args.hasFlag("limit") {
+		n, err := strconv.Atoi(args.flag("limit"))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("--limit must be a positive number")
+		}
+		limit = n
+	}
+
+	matches, err := st.index.Search(args.arg(0), kinds, limit)
+	if err != nil {
+		return nil, err
+	}
+	return searchResult{Term: args.arg(0), Matches: matches}, nil
+}
+
+func commandPokedex(st *state, _ commandArgs) (result, error) {
+	res := pokedexResult{Pokemon: []string{}}
+	for _, entry := range st.pc.Pokemon {
+		if !slices.Contains(res.Pokemon, entry.Pokemon.Name) {
+			res.Pokemon = append(res.Pokemon, entry.Pokemon.Name)
+		}
+	}
+	sort.Strings(res.Pokemon)
+	return res, nil
+}
+
+func commandPC(st *state, _ commandArgs) (result, error) {
+	res := pcResult{Pokemon: []pcInfo{}}
+	for _, entry := range st.pc.Pokemon {
+		res.Pokemon = append(res.Pokemon, pcInfo{
+			ID:       entry.ID,
+			Pokemon:  entry.Pokemon.Name,
+			Nickname: entry.Nickname,
+			Level:    entry.Level,
+			Gender:   entry.Gender,
+			Shiny:    entry.Shiny,
+		})
+	}
+	return res, nil
+}
+
+func commandNickname(st *state, args commandArgs) (result, error) {
+	pokemonName, nickname := args.arg(0), args.arg(1)
+	entry, err := st.pc.Find(pokemonName)
+	if errors.Is(err, trainer.ErrNotCaught) {
+		return nil, didYouMean(err, pokemonName, pokedexNames(st))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nickname == "" {
+		if entry.Nickname == "" {
+			return messageResult{Message: fmt.Sprintf("%s does not have a nickname", entry.Pokemon.Name)}, nil
+		}
+		old := entry.Nickname
+		entry.Nickname = ""
+		st.savePC()
+		return messageResult{Message: fmt.Sprintf("%s is called %s again", old, entry.Pokemon.Name)}, nil
+	}
+	if err := trainer.CheckNickname(nickname); err != nil {
+		return nil, err
+	}
+	for _, other := range st.pc.Pokemon {
+		if strings.EqualFold(other.Nickname, nickname) && other.ID != entry.ID {
+			return nil, fmt.Errorf("#%d is already called %s", other.ID, nickname)
+		}
+	}
+	// A nickname that is also a Pokemon's name would make that name
+	// ambiguous in every command that takes one.
+	names := []string{}
+	for _, other := range st.pc.Pokemon {
+		names = append(names, other.Pokemon.Name)
+	}
+	if slices.Contains(st.withIndex(names, nameindex.Pokemon), strings.ToLower(nickname)) {
+		return nil, fmt.Errorf("%s is the name of a Pokemon, pick another nickname", nickname)
+	}
+	old := entry.Name()
+	entry.Nickname = nickname
+	st.savePC()
+	return messageResult{Message: fmt.Sprintf("%s is now called %s", old, nickname)}, nil
+}
+
+func commandRelease(st *state, args commandArgs) (result, error) {
+	pokemonName := args.arg(0)
+	entry, err := st.pc.Find(pokemonName)
+	if errors.Is(err, trainer.ErrNotCaught) {
+		return nil, didYouMean(err, pokemonName, pokedexNames(st))
+	}
+	if err != nil {
+		return nil, err
+	}
+	released, _ := st.pc.Release(entry.ID)
+	st.savePC()
+	return messageResult{Message: fmt.Sprintf("%s (#%d) was released. Bye-bye, %s!", released.Name(), released.ID, released.Name())}, nil
+}
+
+// savePC writes the save after a change to the PC, warning if it fails.
+func (st *state) savePC() {
+	if err := st.writeSave(); err != nil {
+		st.warnf("your pokedex could not be saved: %v", err)
+	}
+}
+
+func processEncounterResponse(encounter pokeapi.EncounterResponse, areaName string) encounterResult {
+	res := encounterResult{Area: areaName, Pokemon: []string{}}
+	for _, encounterEntry := range encounter.PokemonEncounters {
+		res.Pokemon = append(res.Pokemon, encounterEntry.Pokemon.Name)
+	}
+	return res
+}
+
+// processEncounterDetails lists how each Pokemon in an area is met in one
+// game version. Slots that only differ in level are merged into one row
+// whose chance is their total.
+func processEncounterDetails(encounter pokeapi.EncounterResponse, areaName, version string) (encounterResult, error) {
+	res := encounterResult{Area: areaName, Version: version, Pokemon: []string{}, Encounters: []encounterInfo{}}
+	versions := map[string]bool{}
+	for _, encounterEntry := range encounter.PokemonEncounters {
+		for _, versionDetails := range encounterEntry.VersionDetails {
+			versions[versionDetails.Version.Name] = true
+			if versionDetails.Version.Name != version {
+				continue
+			}
+			res.Pokemon = append(res.Pokemon, encounterEntry.Pokemon.Name)
+			for _, row := range mergeEncounterRows(versionDetails.EncounterDetails) {
+				res.Encounters = append(res.Encounters, encounterInfo{
+					Pokemon:    encounterEntry.Pokemon.Name,
+					Method:     row.Method,
+					Chance:     row.Chance,
+					MinLevel:   row.MinLevel,
+					MaxLevel:   row.MaxLevel,
+					Conditions: row.Conditions,
+				})
+			}
+		}
+	}
+	if len(versions) > 0 && !versions[version] {
+		available := []string{}
+		for name := range versions {
+			available = append(available, name)
+		}
+		sort.Strings(available)
+		return res, fmt.Errorf("%s has no encounters in version %s, try one of: %s", areaName, version, strings.Join(available, ", "))
+	}
+	return res, nil
+}
+
+// encounterRow is one way of meeting a Pokemon in one version of an area.
+type encounterRow struct {
+	Method     string
+	Chance     int
+	MinLevel   int
+	MaxLevel   int
+	Conditions []string
+}
+
+// mergeEncounterRows merges encounter slots that only differ in level
+// into one row per method and conditions, whose chance is their total and
+// whose levels cover them all. Rows keep the order they first appear in.
+func mergeEncounterRows(details []pokeapi.Encounter) []encounterRow {
+	rows := []encounterRow{}
+	index := map[string]int{}
+	for _, detail := range details {
+		conditions := []string{}
+		for _, condition := range detail.ConditionValues {
+			conditions = append(conditions, condition.Name)
+		}
+		key := detail.Method.Name + " " + strings.Join(conditions, ",")
+		if i, ok := index[key]; ok {
+			row := &rows[i]
+			row.Chance += detail.Chance
+			row.MinLevel = min(row.MinLevel, detail.MinLevel)
+			row.MaxLevel = max(row.MaxLevel, detail.MaxLevel)
+			continue
+		}
+		index[key] = len(rows)
+		rows = append(rows, encounterRow{
+			Method:     detail.Method.Name,
+			Chance:     detail.Chance,
+			MinLevel:   detail.MinLevel,
+			MaxLevel:   detail.MaxLevel,
+			Conditions: conditions,
+		})
+	}
+	return rows
+}
+
+// processPokemonEncounters turns the areas a Pokemon lives in into rows
+// grouped by version, then method, with the likeliest areas first.
+func processPokemonEncounters(encounters []pokeapi.LocationAreaEncounter, pokemonName, version string) whereResult {
+	res := whereResult{Pokemon: pokemonName, Encounters: []whereInfo{}}
+	versionOrder, methodOrder := map[string]int{}, map[string]int{}
+	for _, areaEncounter := range encounters {
+		for _, versionDetails := range areaEncounter.VersionDetails {
+			if version != "" && versionDetails.Version.Name != version {
+				continue
+			}
+			if _, ok := versionOrder[versionDetails.Version.Name]; !ok {
+				versionOrder[versionDetails.Version.Name] = len(versionOrder)
+			}
+			for _, row := range mergeEncounterRows(versionDetails.EncounterDetails) {
+				if _, ok := methodOrder[row.Method]; !ok {
+					methodOrder[row.Method] = len(methodOrder)
+				}
+				res.Encounters = append(res.Encounters, whereInfo{
+					Version:    versionDetails.Version.Name,
+					Method:     row.Method,
+					Area:       areaEncounter.LocationArea.Name,
+					Chance:     row.Chance,
+					MinLevel:   row.MinLevel,
+					MaxLevel:   row.MaxLevel,
+					Conditions: row.Conditions,
+				})
+			}
+		}
+	}
+	sort.SliceStable(res.Encounters, func(i, j int) bool {
+		a, b := res.Encounters[i], res.Encounters[j]
+		if a.Version != b.Version {
+			return versionOrder[a.Version] < versionOrder[b.Version]
+		}
+		if a.Method != b.Method {
+			return methodOrder[a.Method] < methodOrder[b.Method]
+		}
+		if a.Chance != b.Chance {
+			return a.Chance > b.Chance
+		}
+		return a.Area < b.Area
+	})
+	return res
+}
+
+// sortEncounters orders an explore result by "name" or, once it has
+// encounter details, by "chance" from most to least likely.
+func sortEncounters(res *encounterResult, by string) {
+	switch by {
+	case "name":
+		sort.Strings(res.Pokemon)
+		sort.SliceStable(res.Encounters, func(i, j int) bool {
+			return res.Encounters[i].Pokemon < res.Encounters[j].Pokemon
+		})
+	case "chance":
+		sort.SliceStable(res.Encounters, func(i, j int) bool {
+			return res.Encounters[i].Chance > res.Encounters[j].Chance
+		})
+		res.Pokemon = res.Pokemon[:0]
+		for _, row := range res.Encounters {
+			if !slices.Contains(res.Pokemon, row.Pokemon) {
+				res.Pokemon = append(res.Pokemon, row.Pokemon)
+			}
+		}
+	}
+}
+
+// processCatchResponse throws one ball from the bag. How likely it is to
+// hold depends on the species' capture rate and the ball's bonus.
+func processCatchResponse(st *state, pokemon pokeapi.Pokemon, species pokeapi.PokemonSpecies, wild trainer.Wild, ball trainer.Ball) (result, error) {
+	if err := st.trainer.Bag.Use(ball); err != nil {
+		return nil, err
+	}
+	res := catchResult{Pokemon: pokemon.Name, Ball: ball.Display}
+	if st.rng.Float64() < trainer.CatchChance(species.CaptureRate, ball) {
+		pokemonCatch(st, pokemon, species, wild)
+		res.Caught = true
+	} else {
+		st.trainer.Wild.Attempts++
+		res.Attempts = st.trainer.Wild.Attempts
+	}
+	st.savePC()
+	return res, nil
+}
+
+func pokemonCatch(st *state, catch pokeapi.Pokemon, species pokeapi.PokemonSpecies, wild trainer.Wild) {
+	st.pc.Add(trainer.NewEntry(catch, species, wild, st.trainer.Location, st.rng))
+	st.trainer.Wild = nil
+}
+
+func commandBattle(st *state, args commandArgs) (result, error) {
+	pokemonName := args.arg(0)
+	wild, err := st.trainer.Target("")
+	if err != nil {
+		return nil, err
+	}
+	entry, err := st.pc.Find(pokemonName)
+	if errors.Is(err, trainer.ErrNotCaught) {
+		return nil, didYouMean(err, pokemonName, pokedexNames(st))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defeated, err := st.client.Pokemon(wild.Species)
+	if err != nil {
+		return nil, err
+	}
+	species, err := st.client.Species(entry.Species())
+	if err != nil {
+		return nil, err
+	}
+	xp, levels := entry.Defeat(defeated, wild.Level, species.GrowthRate.Name)
+	st.trainer.Wild = nil
+	st.savePC()
+	return battleResult{Pokemon: entry.Name(), Wild: wild.Species, XP: xp, Level: entry.Level, LevelsGained: levels}, nil
+}
+
+func commandBag(st *state, _ commandArgs) (result, error) {
+	res := bagResult{Balls: []ballInfo{}}
+	for _, ball := range trainer.Balls {
+		res.Balls = append(res.Balls, ballInfo{Name: ball.Name, Display: ball.Display, Count: st.trainer.Bag[ball.Name]})
+	}
+	return res, nil
+}
+
+var langFlag = flagSpec{name: "lang", value: "code", description: "Language of the genus and Pokedex entry (default en)"}
+
+func init() {
+	commands = map[string]cliCommand{
+		"help": {
+			name:        "help",
+			description: "Displays this help message, or details about one command",
+			args:        []argSpec{{name: "command", optional: true, complete: commandNames}},
+			callback:    commandHelp,
+		},
+		"exit": {
+			name:        "exit",
+			description: "Exit the Pokedex",
+			callback:    commandExit,
+		},
+		"set": {
+			name:        "set",
+			description: "Changes a setting: 'set output text|json' or 'set prefetch-encounters on|off'",
+			args:        []argSpec{{name: "setting"}, {name: "value"}},
+			callback:    commandSet,
+		},
+		"map": {
+			name:        "map",
+			description: "Displays the next page of location areas, or the given page",
+			args:        []argSpec{{name: "page", optional: true, complete: pageMoveNames}},
+			flags:       pageFlags,
+			callback:    commandMap,
+		},
+		"mapb": {
+			name:        "mapb",
+			description: "Displays the previous page of location areas",
+			flags:       pageFlags,
+			callback:    commandMapb,
+		},
+		"region": {
+			name:        "region",
+			description: "Displays the locations in a region",
+			args:        []argSpec{{name: "region_name", complete: knownRegionNames}},
+			callback:    commandRegion,
+		},
+		"location": {
+			name:        "location",
+			description: "Displays the areas you can explore in a location",
+			args:        []argSpec{{name: "location_name", complete: knownLocationNames}},
+			callback:    commandLocation,
+		},
+		"explore": {
+			name:        "explore",
+			description: "Displays the poke youman you can find in the area",
+			args:        []argSpec{{name: "area_name", complete: areaNames}},
+			flags: []flagSpec{
+				{name: "version", value: "version", description: "Show chance, levels, method and conditions in one game version, e.g. red"},
+				{name: "sort", value: "chance|name", description: "Order the Pokemon by encounter chance or by name"},
+			},
+			callback: commandExplore,
+		},
+		"goto": {
+			name:        "goto",
+			description: "Moves you to a location area, or shows where you are",
+			args:        []argSpec{{name: "area_name", optional: true, complete: areaNames}},
+			callback:    commandGoto,
+		},
+		"walk": {
+			name:        "walk",
+			description: "Walks around your area until a wild Pokemon appears, sometimes finding a ball on the way",
+			callback:    commandWalk,
+		},
+		"catch": {
+			name:        "catch",
+			description: "Attempts to catch the wild Pokemon in front of you",
+			args:        []argSpec{{name: "pokemon_name", optional: true, complete: wildPokemonNames}},
+			flags: []flagSpec{
+				{name: "ball", value: "poke|great|ultra|master", description: "Throw this kind of ball (default poke)"},
+			},
+			callback: commandCatch,
+		},
+		"battle": {
+			name:        "battle",
+			description: "Sends one of your Pokemon to defeat the wild Pokemon in front of you, gaining experience and levels",
+			args:        []argSpec{{name: "pokemon", complete: pokedexNames}},
+			callback:    commandBattle,
+		},
+		"where": {
+			name:        "where",
+			description: "Shows the areas where a Pokemon can be found in the wild",
+			args:        []argSpec{{name: "pokemon_name", complete: lastEncounterNames}},
+			flags: []flagSpec{
+				{name: "version", value: "version", description: "Only show one game version, e.g. red"},
+			},
+			callback: commandWhere,
+		},
+		"bag": {
+			name:        "bag",
+			description: "Shows the balls in your bag",
+			callback:    commandBag,
+		},
+		"inspect": {
+			name:        "inspect",
+			description: "Shows you information about the Pokemon",
+			args:        []argSpec{{name: "pokemon_name", complete: pokedexNames}},
+			flags: []flagSpec{
+				{name: "species", description: "Also show the species' capture rate, growth rate and Pokedex entry"},
+				langFlag,
+			},
+			callback: commandInspect,
+		},
+		"evolution": {
+			name:        "evolution",
+			description: "Shows how a Pokemon evolves and which stages you have caught",
+			args:        []argSpec{{name: "pokemon_name", complete: pokedexNames}},
+			callback:    commandEvolution,
+		},
+		"evolve": {
+			name:        "evolve",
+			description: "Evolves a Pokemon in your pokedex once it meets the requirements",
+			args:        []argSpec{{name: "pokemon_name", complete: pokedexNames}},
+			flags: []flagSpec{
+				{name: "into", value: "species", description: "Pick the evolution when there is more than one"},
+				{name: "item", value: "item", description: "Use or hold an item, e.g. water-stone"},
+				{name: "trade", description: "Trade the Pokemon to evolve it"},
+			},
+			callback: commandEvolve,
+		},
+		"species": {
+			name:        "species",
+			description: "Shows the capture rate, growth rate, rarity and Pokedex entry of a species",
+			args:        []argSpec{{name: "species_name", complete: lastEncounterNames}},
+			flags:       []flagSpec{langFlag},
+			callback:    commandSpecies,
+		},
+		"search": {
+			name:        "search",
+			description: "Searches every Pokemon and location area by prefix, substring or close spelling",
+			args:        []argSpec{{name: "term"}},
+			flags: []flagSpec{
+				{name: "kind", value: "pokemon|area|location|region", description: "Only search one kind of name"},
+				{name: "limit", value: "n", description: "Show at most n matches (default 20)"},
+			},
+			callback: commandSearch,
+		},
+		"pokedex": {
+			name:        "pokedex",
+			description: "Shows you the Pokemon in your Pokedex",
+			callback:    commandPokedex,
+		},
+		"pc": {
+			name:        "pc",
+			description: "Lists every Pokemon you have caught with its ID",
+			callback:    commandPC,
+		},
+		"nickname": {
+			name:        "nickname",
+			description: "Gives a caught Pokemon a nickname, or removes it when none is given",
+			args:        []argSpec{{name: "pokemon", complete: pokedexNames}, {name: "nickname", optional: true, keepCase: true}},
+			callback:    commandNickname,
+		},
+		"release": {
+			name:        "release",
+			description: "Releases a caught Pokemon back into the wild",
+			args:        []argSpec{{name: "pokemon", complete: pokedexNames}},
+			callback:    commandRelease,
+		},
+	}
+	for _, spec := range listingSpecs {
+		if spec.command == "" {
+			continue
+		}
+		commands[spec.command] = cliCommand{
+			name:        spec.command,
+			description: spec.description,
+			args:        []argSpec{{name: "page", optional: true, complete: pageMoveNames}},
+			flags:       pageFlags,
+			callback:    commandPage(spec.key),
+		}
+	}
+}
@@ -0,0 +1,966 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/nameindex"
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+	"github.com/thmastin/pokedexcli/internal/pokecache"
+	"github.com/thmastin/pokedexcli/internal/trainer"
+)
+
+// fakePokeAPI serves a tiny slice of PokeAPI and remembers every request.
+type fakePokeAPI struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *fakePokeAPI) requested(uri string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Contains(f.requests, uri)
+}
+
+// newFakePokeAPI starts a fakePokeAPI. Listing endpoints are paged from
+// listings; everything else is a fixed body from responses, in which
+// {base} is replaced by the server's URL.
+func newFakePokeAPI(t *testing.T) *fakePokeAPI {
+	t.Helper()
+	listings := map[string][]string{
+		"/api/v2/location-area/": {"canalave-city-area", "eterna-city-area", "pastoria-city-area"},
+		"/api/v2/pokemon/":       {"pikachu", "staryu", "tentacool"},
+		"/api/v2/region/":        {"kanto", "johto", "hoenn", "sinnoh", "unova"},
+		"/api/v2/location/":      {"canalave-city", "eterna-city", "pastoria-city"},
+	}
+	responses := map[string]string{
+		"/api/v2/location-area/canalave-city-area": `{"name": "canalave-city-area", "pokemon_encounters": [
+			{"pokemon": {"name": "tentacool"}, "version_details": [
+				{"version": {"name": "diamond"}, "encounter_details": [
+					{"chance": 60, "min_level": 20, "max_level": 30, "method": {"name": "surf"}, "condition_values": []},
+					{"chance": 30, "min_level": 20, "max_level": 30, "method": {"name": "surf"}, "condition_values": []},
+					{"chance": 5, "min_level": 10, "max_level": 10, "method": {"name": "old-rod"}, "condition_values": []}]},
+				{"version": {"name": "pearl"}, "encounter_details": [
+					{"chance": 90, "min_level": 20, "max_level": 30, "method": {"name": "surf"}, "condition_values": []}]}]},
+			{"pokemon": {"name": "staryu"}, "version_details": [
+				{"version": {"name": "diamond"}, "encounter_details": [
+					{"chance": 15, "min_level": 15, "max_level": 25, "method": {"name": "good-rod"},
+						"condition_values": [{"name": "time-night"}]}]}]}]}`,
+		"/api/v2/location-area/pastoria-city-area": `{"name": "pastoria-city-area", "pokemon_encounters": [
+			{"pokemon": {"name": "tentacool"}, "version_details": [
+				{"version": {"name": "diamond"}, "encounter_details": [
+					{"chance": 100, "min_level": 20, "max_level": 20, "method": {"name": "surf"}, "condition_values": []}]}]}]}`,
+		"/api/v2/region/sinnoh": `{"id": 4, "name": "sinnoh", "main_generation": {"name": "generation-iv"},
+			"locations": [{"name": "canalave-city"}, {"name": "eterna-city"}]}`,
+		"/api/v2/location/canalave-city": `{"id": 1, "name": "canalave-city", "region": {"name": "sinnoh"},
+			"areas": [{"name": "canalave-city-area"}]}`,
+		"/api/v2/pokemon/tentacool/encounters": `[
+			{"location_area": {"name": "canalave-city-area"}, "version_details": [
+				{"version": {"name": "diamond"}, "max_chance": 95, "encounter_details": [
+					{"chance": 60, "min_level": 20, "max_level": 30, "method": {"name": "surf"}, "condition_values": []},
+					{"chance": 30, "min_level": 20, "max_level": 30, "method": {"name": "surf"}, "condition_values": []},
+					{"chance": 5, "min_level": 10, "max_level": 10, "method": {"name": "old-rod"}, "condition_values": []}]},
+				{"version": {"name": "pearl"}, "max_chance": 90, "encounter_details": [
+					{"chance": 90, "min_level": 20, "max_level": 30, "method": {"name": "surf"}, "condition_values": []}]}]},
+			{"location_area": {"name": "pastoria-city-area"}, "version_details": [
+				{"version": {"name": "diamond"}, "max_chance": 100, "encounter_details": [
+					{"chance": 100, "min_level": 20, "max_level": 40, "method": {"name": "surf"},
+						"condition_values": [{"name": "swarm-no"}]}]}]}]`,
+		"/api/v2/pokemon/pikachu/encounters": `[]`,
+		"/api/v2/pokemon-species/tentacool": `{"id": 72, "name": "tentacool", "capture_rate": 190,
+			"base_happiness": 50, "growth_rate": {"name": "slow"},
+			"evolution_chain": {"url": "{base}/api/v2/evolution-chain/31/"},
+			"genera": [{"genus": "Quallen-Pokémon", "language": {"name": "de"}}, {"genus": "Jellyfish Pokémon", "language": {"name": "en"}}],
+			"flavor_text_entries": [
+				{"flavor_text": "Drifts in shallow\nseas.", "language": {"name": "en"}, "version": {"name": "red"}},
+				{"flavor_text": "Its body is almost\fentirely water.", "language": {"name": "en"}, "version": {"name": "diamond"}}]}`,
+		"/api/v2/pokemon-species/eevee": `{"id": 133, "name": "eevee", "capture_rate": 45,
+			"evolution_chain": {"url": "{base}/api/v2/evolution-chain/67/"}}`,
+		"/api/v2/pokemon-species/ditto": `{"id": 132, "name": "ditto", "capture_rate": 35,
+			"evolution_chain": {"url": "{base}/api/v2/evolution-chain/66/"}}`,
+		"/api/v2/evolution-chain/31": `{"id": 31, "chain": {"species": {"name": "tentacool"}, "evolution_details": [], "evolves_to": [
+			{"species": {"name": "tentacruel"}, "evolves_to": [],
+				"evolution_details": [{"trigger": {"name": "level-up"}, "min_level": 30}]}]}}`,
+		"/api/v2/evolution-chain/66": `{"id": 66, "chain": {"species": {"name": "ditto"}, "evolution_details": [], "evolves_to": []}}`,
+		"/api/v2/evolution-chain/67": `{"id": 67, "chain": {"species": {"name": "eevee"}, "evolution_details": [], "evolves_to": [
+			{"species": {"name": "vaporeon"}, "evolves_to": [],
+				"evolution_details": [{"trigger": {"name": "use-item"}, "item": {"name": "water-stone"}}]},
+			{"species": {"name": "espeon"}, "evolves_to": [],
+				"evolution_details": [{"trigger": {"name": "level-up"}, "min_happiness": 160, "time_of_day": "day"}]},
+			{"species": {"name": "leafeon"}, "evolves_to": [], "evolution_details": [
+				{"trigger": {"name": "level-up"}, "location": {"name": "eterna-forest"}},
+				{"trigger": {"name": "use-item"}, "item": {"name": "leaf-stone"}}]}]}}`,
+		"/api/v2/pokemon-species/staryu": `{"id": 120, "name": "staryu", "capture_rate": 225}`,
+		"/api/v2/pokemon/tentacruel":     `{"id": 73, "name": "tentacruel", "species": {"name": "tentacruel"}}`,
+		"/api/v2/pokemon/vaporeon":       `{"id": 134, "name": "vaporeon", "species": {"name": "vaporeon"}}`,
+		"/api/v2/pokemon/staryu":         `{"id": 120, "name": "staryu", "base_experience": 68}`,
+		"/api/v2/pokemon/tentacool": `{"id": 72, "name": "tentacool", "base_experience": 67, "height": 9, "weight": 455,
+			"stats": [{"base_stat": 40, "effort": 1, "stat": {"name": "hp"}}], "types": [{"slot": 1, "type": {"name": "water"}}]}`,
+	}
+	server := &fakePokeAPI{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		server.requests = append(server.requests, r.URL.RequestURI())
+		server.mu.Unlock()
+		if names, ok := listings[r.URL.Path]; ok {
+			json.NewEncoder(w).Encode(fakeListing(server.URL+r.URL.Path, names, r.URL.Query()))
+			return
+		}
+		body, ok := responses[strings.TrimSuffix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, strings.ReplaceAll(body, "{base}", server.URL))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fakeListing(endpoint string, names []string, query url.Values) pokeapi.NamedAPIResourceList {
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil {
+		limit = 20
+	}
+	list := pokeapi.NamedAPIResourceList{Count: len(names), Results: []pokeapi.NamedAPIResource{}}
+	for i := offset; i < len(names) && i < offset+limit; i++ {
+		list.Results = append(list.Results, pokeapi.NamedAPIResource{Name: names[i], URL: endpoint + names[i] + "/"})
+	}
+	if offset+limit < len(names) {
+		next := fmt.Sprintf("%s?offset=%d&limit=%d", endpoint, offset+limit, limit)
+		list.Next = &next
+	}
+	if offset > 0 {
+		previous := fmt.Sprintf("%s?offset=%d&limit=%d", endpoint, max(0, offset-limit), limit)
+		list.Previous = &previous
+	}
+	return list
+}
+
+func newTestState(t *testing.T) (*state, *bytes.Buffer) {
+	t.Helper()
+	server := newFakePokeAPI(t)
+	var out bytes.Buffer
+	st := newState(pokeapi.NewClient(pokeapi.WithBaseURL(server.URL+"/api/v2")), &out, io.Discard)
+	st.rng = rand.New(rand.NewSource(1))
+	return st, &out
+}
+
+func TestREPLSession(t *testing.T) {
+	st, out := newTestState(t)
+	input := strings.Join([]string{
+		"map --size 2",
+		"map",
+		"map",
+		"mapb",
+		"explore canalave-city-area",
+		"explore nowhere",
+		"goto pastoria-city-area",
+		"walk",
+		"catch tentacool --ball master",
+		"inspect tentacool",
+		"pokedex",
+		"exit",
+		"help",
+	}, "\n")
+
+	startREPL(st, bufio.NewScanner(strings.NewReader(input)))
+
+	expected := []string{
+		"Pokedex > canalave-city-area\neterna-city-area\npage 1 of 2 (3 areas total)\n",
+		"Pokedex > pastoria-city-area\npage 2 of 2 (3 areas total)\n",
+		"Pokedex > you're on the last page\n",
+		"Pokedex > canalave-city-area\neterna-city-area\npage 1 of 2 (3 areas total)\n",
+		"Exploring canalave-city-area...\nFound Pokemon:\n - tentacool\n - staryu\n",
+		"Error executing explore command: invalid area: nowhere",
+		"You are now in pastoria-city-area, 1 species of Pokemon live here\n",
+		"A wild tentacool (lv 20) appeared in pastoria-city-area!\n  -hp: ",
+		"Throwing a Master Ball at tentacool...tentacool was caught!\n",
+		"Name: tentacool\nHeight: 9\nWeight: 455\nStats:\n  -hp: 40\nTypes:\n  -water\n",
+		"Your Pokedex:\n  - tentacool\n",
+		"Closing the Pokedex... Goodbye!\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Welcome to the Pokedex!") {
+		t.Errorf("expected the session to stop at exit")
+	}
+}
+
+func TestCommandsJSONOutput(t *testing.T) {
+	st, out := newTestState(t)
+	runScript(st, strings.NewReader("goto pastoria-city-area\nwalk\n"), false)
+	out.Reset()
+	script := "set output json\nexplore canalave-city-area\ncatch tentacool --ball master\ncatch tentacool\n"
+
+	status := runScript(st, strings.NewReader(script), true)
+	if status != 1 {
+		t.Errorf("Expected status: 1, Got: %v", status)
+	}
+
+	expected := `{"message":"output set to json"}
+{"area":"canalave-city-area","pokemon":["tentacool","staryu"]}
+{"pokemon":"tentacool","ball":"Master Ball","caught":true,"attempts":0}
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+}
+
+func TestCompleteLine(t *testing.T) {
+	st, _ := newTestState(t)
+	if status := runScript(st, strings.NewReader("map\ngoto pastoria-city-area\nwalk\ncatch tentacool --ball master\nwalk\n"), false); status != 0 {
+		t.Fatalf("setup script failed with status %v", status)
+	}
+
+	cases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "command names",
+			input:    "ma",
+			expected: []string{"map", "mapb"},
+		},
+		{
+			name:     "areas from map listings",
+			input:    "explore e",
+			expected: []string{"explore eterna-city-area"},
+		},
+		{
+			name:     "the wild pokemon",
+			input:    "catch ",
+			expected: []string{"catch tentacool"},
+		},
+		{
+			name:     "pokedex entries",
+			input:    "inspect T",
+			expected: []string{"inspect tentacool"},
+		},
+		{
+			name:     "help completes command names",
+			input:    "help ins",
+			expected: []string{"help inspect"},
+		},
+		{
+			name:     "nothing for a flag value",
+			input:    "explore --version ",
+			expected: []string{},
+		},
+		{
+			name:     "nothing for the ball flag value",
+			input:    "catch --ball ",
+			expected: []string{},
+		},
+		{
+			name:     "positional after a flag value",
+			input:    "catch --ball master ",
+			expected: []string{"catch --ball master tentacool"},
+		},
+		{
+			name:     "no completion past the declared arguments",
+			input:    "inspect tentacool ",
+			expected: []string{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := completeLine(st, c.input)
+			if strings.Join(actual, "|") != strings.Join(c.expected, "|") {
+				t.Errorf("Expected: %v, Got: %v", c.expected, actual)
+			}
+		})
+	}
+
+	fresh, _ := newTestState(t)
+	if actual := completeLine(fresh, "goto p"); len(actual) != 0 {
+		t.Errorf("expected completion not to load the name index, got %v", actual)
+	}
+	if _, err := fresh.index.Names(nameindex.Area); err != nil {
+		t.Fatalf("failed to load the area index: %v", err)
+	}
+	if actual := completeLine(fresh, "goto p"); strings.Join(actual, "|") != "goto pastoria-city-area" {
+		t.Errorf("expected areas from the loaded name index, got %v", actual)
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	st, _ := newTestState(t)
+	runScript(st, strings.NewReader("map\n"), false)
+
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "unknown command",
+			input:    "mpa",
+			expected: `Unknown command, did you mean "map"?`,
+		},
+		{
+			name:     "unknown command without a close match",
+			input:    "teleport",
+			expected: "Unknown command",
+		},
+		{
+			name:     "area from an earlier listing",
+			input:    "explore eterna-cty-area",
+			expected: `Error executing explore command: invalid area: eterna-cty-area. please use the pokedex 'map' command to see valid areas, did you mean "eterna-city-area"?`,
+		},
+		{
+			name:     "pokemon from the full name index",
+			input:    "where pikchu",
+			expected: `Error executing where command: invalid pokemon: pikchu. please use the pokedex 'search' command to find valid pokemon, did you mean "pikachu"?`,
+		},
+		{
+			name:     "help for an unknown command",
+			input:    "help inspcet",
+			expected: `Error executing help command: Unknown command, did you mean "inspect"?`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := runCommand(st, c.input)
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("Expected: %v, Got: %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestSearchCommand(t *testing.T) {
+	st, out := newTestState(t)
+	script := "search ta\nsearch eterna --kind area\nsearch staryuu --limit 1\nsearch zzz\n"
+	if status := runScript(st, strings.NewReader(script), false); status != 0 {
+		t.Fatalf("script failed with status %v", status)
+	}
+
+	expected := `Matches for "ta":
+ - tentacool (pokemon)
+ - staryu (pokemon)
+ - canalave-city-area (area)
+ - pastoria-city-area (area)
+Matches for "eterna":
+ - eterna-city-area (area)
+Matches for "staryuu":
+ - staryu (pokemon)
+Nothing matches "zzz"
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+}
+
+func TestMapPaging(t *testing.T) {
+	st, out := newTestState(t)
+	script := "mapb\nmap 2 --size 1\nmap --size 2\nmap 9\nmap\nmapb\n"
+
+	status := runScript(st, strings.NewReader(script), true)
+	if status != 1 {
+		t.Errorf("Expected status: 1, Got: %v", status)
+	}
+
+	expected := `you're on the first page
+eterna-city-area
+page 2 of 3 (3 areas total)
+canalave-city-area
+eterna-city-area
+page 1 of 2 (3 areas total)
+pastoria-city-area
+page 2 of 2 (3 areas total)
+canalave-city-area
+eterna-city-area
+page 1 of 2 (3 areas total)
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+}
+
+func TestListingsPageIndependently(t *testing.T) {
+	st, out := newTestState(t)
+	script := "regions --size 2\nmap\nregions last\nregions\nregions prev\nmapb\nregions first\nset output json\nregions 2\n"
+	if status := runScript(st, strings.NewReader(script), false); status != 0 {
+		t.Fatalf("script failed with status %v", status)
+	}
+
+	expected := `kanto
+johto
+page 1 of 3 (5 regions total)
+canalave-city-area
+eterna-city-area
+pastoria-city-area
+page 1 of 1 (3 areas total)
+unova
+page 3 of 3 (5 regions total)
+you're on the last page
+hoenn
+sinnoh
+page 2 of 3 (5 regions total)
+you're on the first page
+kanto
+johto
+page 1 of 3 (5 regions total)
+{"message":"output set to json"}
+{"has_next":true,"has_previous":true,"page":2,"page_size":2,"pages":3,"regions":["hoenn","sinnoh"],"total":5}
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+}
+
+func TestMapPrefetchesAdjacentPages(t *testing.T) {
+	server := newFakePokeAPI(t)
+	client := pokeapi.NewClient(pokeapi.WithBaseURL(server.URL+"/api/v2"), pokeapi.WithCache(pokecache.NewCache(time.Minute)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.StartPrefetch(ctx, 2)
+	st := newState(client, io.Discard, io.Discard)
+
+	if status := runScript(st, strings.NewReader("map 2 --size 1\n"), false); status != 0 {
+		t.Fatalf("script failed with status %v", status)
+	}
+	client.WaitPrefetch()
+	for _, uri := range []string{"/api/v2/location-area/?offset=0&limit=1", "/api/v2/location-area/?offset=2&limit=1"} {
+		if !server.requested(uri) {
+			t.Errorf("expected %s to be prefetched", uri)
+		}
+	}
+	if server.requested("/api/v2/location-area/canalave-city-area") {
+		t.Errorf("expected encounters not to be prefetched by default")
+	}
+
+	if status := runScript(st, strings.NewReader("set prefetch-encounters on\nmapb\n"), false); status != 0 {
+		t.Fatalf("script failed with status %v", status)
+	}
+	client.WaitPrefetch()
+	if !server.requested("/api/v2/location-area/canalave-city-area") {
+		t.Errorf("expected encounters of the listed area to be prefetched")
+	}
+}
+
+func TestRegionHierarchy(t *testing.T) {
+	st, out := newTestState(t)
+	script := "region sinnoh\nlocation canalave-city\nexplore canalave-city-area\n"
+	if status := runScript(st, strings.NewReader(script), false); status != 0 {
+		t.Fatalf("script failed with status %v", status)
+	}
+
+	expected := `Locations in sinnoh (generation-iv):
+ - canalave-city
+ - eterna-city
+Areas in canalave-city (sinnoh):
+ - canalave-city-area
+Exploring canalave-city-area...
+Found Pokemon:
+ - tentacool
+ - staryu
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+
+	completions := completeLine(st, "location e")
+	if len(completions) != 1 || completions[0] != "location eterna-city" {
+		t.Errorf("Expected: [location eterna-city], Got: %v", completions)
+	}
+
+	var errOut bytes.Buffer
+	st.errOut = &errOut
+	runScript(st, strings.NewReader("region sinoh\nlocation pastoria-cty\n"), true)
+	for _, want := range []string{`did you mean "sinnoh"?`, `did you mean "pastoria-city"?`} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("expected errors to contain %q, got:\n%s", want, errOut.String())
+		}
+	}
+}
+
+func TestExploreVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{
+			name:    "details in one version",
+			command: "explore canalave-city-area --version diamond",
+			expected: `Exploring canalave-city-area in diamond...
+Found Pokemon:
+ - tentacool: 90% surf, lv 20-30
+ - tentacool: 5% old-rod, lv 10
+ - staryu: 15% good-rod, lv 15-25 (time-night)
+`,
+		},
+		{
+			name:    "sorted by chance",
+			command: "explore canalave-city-area --version diamond --sort chance",
+			expected: `Exploring canalave-city-area in diamond...
+Found Pokemon:
+ - tentacool: 90% surf, lv 20-30
+ - staryu: 15% good-rod, lv 15-25 (time-night)
+ - tentacool: 5% old-rod, lv 10
+`,
+		},
+		{
+			name:     "version filters pokemon",
+			command:  "explore canalave-city-area --version pearl",
+			expected: "Exploring canalave-city-area in pearl...\nFound Pokemon:\n - tentacool: 90% surf, lv 20-30\n",
+		},
+		{
+			name:     "sorted by name without a version",
+			command:  "explore canalave-city-area --sort name",
+			expected: "Exploring canalave-city-area...\nFound Pokemon:\n - staryu\n - tentacool\n",
+		},
+		{
+			name:     "unknown version",
+			command:  "explore canalave-city-area --version red",
+			expected: "Error executing explore command: canalave-city-area has no encounters in version red, try one of: diamond, pearl\n",
+		},
+		{
+			name:     "chance needs a version",
+			command:  "explore canalave-city-area --sort chance",
+			expected: "Error executing explore command: --sort chance needs --version\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			st, out := newTestState(t)
+			st.errOut = out
+			runScript(st, strings.NewReader(c.command), false)
+			if out.String() != c.expected {
+				t.Errorf("Expected: %q, Got: %q", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestWhereCommand(t *testing.T) {
+	server := newFakePokeAPI(t)
+	var out bytes.Buffer
+	client := pokeapi.NewClient(pokeapi.WithBaseURL(server.URL+"/api/v2"), pokeapi.WithCache(pokecache.NewCache(time.Minute)))
+	st := newState(client, &out, &out)
+	script := "where tentacool\nwhere tentacool --version pearl\nwhere pikachu\nwhere tentacol\n"
+	runScript(st, strings.NewReader(script), true)
+
+	expected := `tentacool can be found in:
+diamond:
+  surf:
+   - pastoria-city-area: 100%, lv 20-40 (swarm-no)
+   - canalave-city-area: 90%, lv 20-30
+  old-rod:
+   - canalave-city-area: 5%, lv 10
+pearl:
+  surf:
+   - canalave-city-area: 90%, lv 20-30
+tentacool can be found in:
+pearl:
+  surf:
+   - canalave-city-area: 90%, lv 20-30
+pikachu cannot be found in the wild
+Error executing where command: invalid pokemon: tentacol. please use the pokedex 'search' command to find valid pokemon, did you mean "tentacool"?
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+
+	count := 0
+	for _, uri := range server.requests {
+		if uri == "/api/v2/pokemon/tentacool/encounters" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected the encounters to be fetched once, Got: %v", count)
+	}
+	if !st.knownAreas["pastoria-city-area"] {
+		t.Errorf("expected where to remember the areas it showed")
+	}
+}
+
+func TestWalkAndCatch(t *testing.T) {
+	st, out := newTestState(t)
+	st.errOut = out
+	script := "walk\ngoto\ngoto pastoria-city-area\ngoto\ncatch\nwalk\ncatch pikachu\ncatch tentacol\ncatch --ball master\n"
+	runScript(st, strings.NewReader(script), true)
+
+	expected := []string{
+		"Error executing walk command: you are not in any area, use 'goto <area>' first\n",
+		"Error executing goto command: you are not in any area, use 'goto <area>' first\n",
+		"You are now in pastoria-city-area, 1 species of Pokemon live here\n",
+		"You are in pastoria-city-area\n",
+		"Error executing catch command: there is no wild Pokemon around, use 'walk' to look for one\n",
+		"A wild tentacool (lv 20) appeared in pastoria-city-area!\n  -hp: ",
+		"Error executing catch command: pikachu is not here, the wild Pokemon is a tentacool\n",
+		`Error executing catch command: tentacol is not here, the wild Pokemon is a tentacool, did you mean "tentacool"?` + "\n",
+		"Throwing a Master Ball at tentacool...tentacool was caught!\n",
+	}
+	rest := out.String()
+	for _, want := range expected {
+		i := strings.Index(rest, want)
+		if i < 0 {
+			t.Fatalf("expected %q in order, got:\n%s", want, out.String())
+		}
+		rest = rest[i+len(want):]
+	}
+	if st.trainer.Wild != nil {
+		t.Errorf("expected the caught Pokemon to leave the area")
+	}
+}
+
+func TestWalkIsWeightedByEncounterChance(t *testing.T) {
+	st, _ := newTestState(t)
+	runScript(st, strings.NewReader("goto canalave-city-area\n"), false)
+
+	// staryu makes up 15% of encounters, tentacool 95%.
+	counts := map[string]int{}
+	for range 200 {
+		if status := runScript(st, strings.NewReader("walk\n"), false); status != 0 {
+			t.Fatalf("walk failed with status %v", status)
+		}
+		counts[st.trainer.Wild.Species]++
+	}
+	if counts["staryu"] == 0 || counts["staryu"] > counts["tentacool"]/3 {
+		t.Errorf("expected staryu to be rare, got %v", counts)
+	}
+}
+
+func TestCatchUsesBalls(t *testing.T) {
+	st, out := newTestState(t)
+	st.errOut = out
+	st.trainer.Bag = trainer.Bag{"poke-ball": 1, "ultra-ball": 1}
+	runScript(st, strings.NewReader("goto pastoria-city-area\nwalk\n"), false)
+	out.Reset()
+
+	script := "catch --ball moon\ncatch --ball master\nbag\n"
+	runScript(st, strings.NewReader(script), true)
+	expected := `Error executing catch command: unknown ball: moon, use poke, great, ultra or master
+Error executing catch command: you have run out of Master Balls
+Your bag:
+  - Poke Ball: 1
+  - Great Ball: 0
+  - Ultra Ball: 1
+  - Master Ball: 0
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+
+	out.Reset()
+	runScript(st, strings.NewReader("catch\ncatch --ball ultra\n"), true)
+	if !strings.Contains(out.String(), "Throwing a Poke Ball at tentacool...") || st.trainer.Bag["poke-ball"] != 0 {
+		t.Errorf("expected the poke ball to be thrown, got:\n%s", out.String())
+	}
+}
+
+func TestSpeciesCommand(t *testing.T) {
+	st, out := newTestState(t)
+	st.errOut = out
+	script := "species tentacool\nspecies tentacool --lang de\nspecies tentacol\n"
+	runScript(st, strings.NewReader(script), true)
+
+	expected := `Species: tentacool
+Genus: Jellyfish Pokémon
+Capture rate: 190
+Base happiness: 50
+Growth rate: slow
+Its body is almost entirely water.
+Species: tentacool
+Genus: Quallen-Pokémon
+Capture rate: 190
+Base happiness: 50
+Growth rate: slow
+Error executing species command: invalid species: tentacol. please use the pokedex 'search' command to find valid pokemon, did you mean "tentacool"?
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+}
+
+func TestInspectSpecies(t *testing.T) {
+	st, out := newTestState(t)
+	runScript(st, strings.NewReader("goto pastoria-city-area\nwalk\ncatch --ball master\n"), false)
+	out.Reset()
+
+	if status := runScript(st, strings.NewReader("set output json\ninspect tentacool --species\n"), false); status != 0 {
+		t.Fatalf("script failed with status %v", status)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var res inspectResult
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &res); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Species == nil || res.Species.CaptureRate != 190 || res.Species.Genus != "Jellyfish Pokémon" {
+		t.Errorf("expected the species of tentacool, got %+v", res.Species)
+	}
+}
+
+func TestEvolutionCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		setup    string
+		command  string
+		expected string
+	}{
+		{
+			name:     "branches and triggers",
+			command:  "evolution eevee",
+			expected: "eevee\n|- vaporeon (use water-stone)\n|- espeon (level up, happiness 160, during the day)\n`- leafeon (level up, at eterna-forest or use leaf-stone)\n",
+		},
+		{
+			name:     "caught stages are marked",
+			setup:    "goto pastoria-city-area\nwalk\ncatch --ball master\n",
+			command:  "evolution tentacool",
+			expected: "tentacool [caught]\n`- tentacruel (level 30)\n",
+		},
+		{
+			name:     "nicknames resolve to their species",
+			setup:    "goto pastoria-city-area\nwalk\ncatch --ball master\nnickname 1 Jelly\n",
+			command:  "evolution jelly",
+			expected: "tentacool [caught]\n`- tentacruel (level 30)\n",
+		},
+		{
+			name:     "no evolutions",
+			command:  "evolution ditto",
+			expected: "ditto\n",
+		},
+		{
+			name:     "unknown pokemon",
+			command:  "evolution eevie",
+			expected: `Error executing evolution command: invalid species: eevie. please use the pokedex 'search' command to find valid pokemon` + "\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			st, out := newTestState(t)
+			st.errOut = out
+			runScript(st, strings.NewReader(c.setup), false)
+			out.Reset()
+			runScript(st, strings.NewReader(c.command), false)
+			if out.String() != c.expected {
+				t.Errorf("Expected: %q, Got: %q", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestEvolveCommand(t *testing.T) {
+	st, out := newTestState(t)
+	st.errOut = out
+	st.pc.Add(trainer.Entry{Pokemon: pokeapi.Pokemon{Name: "tentacool"}, Level: 25})
+	st.pc.Add(trainer.Entry{Pokemon: pokeapi.Pokemon{Name: "eevee"}, Level: 10})
+
+	script := strings.Join([]string{
+		"evolve tentacol",
+		"evolve tentacool",
+		"evolve eevee",
+		"evolve eevee --into vaporean",
+		"evolve eevee --into vaporeon",
+		"evolve eevee --into vaporeon --item water-stone",
+	}, "\n")
+	runScript(st, strings.NewReader(script), true)
+
+	expected := `Error executing evolve command: tentacol is not in your pokedex, did you mean "tentacool"?
+Error executing evolve command: tentacool cannot evolve into tentacruel yet, it needs level 30
+Error executing evolve command: eevee can evolve into vaporeon, espeon, leafeon, pick one with --into
+Error executing evolve command: eevee cannot evolve into vaporean, only into vaporeon, espeon, leafeon, did you mean "vaporeon"?
+Error executing evolve command: eevee cannot evolve into vaporeon yet, it needs --item water-stone
+What? eevee is evolving!
+Congratulations! Your eevee evolved into vaporeon!
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+
+	vaporeon := st.pc.Pokemon[1]
+	if vaporeon.ID != 2 || vaporeon.Pokemon.Name != "vaporeon" {
+		t.Fatalf("expected eevee to become vaporeon, got %v", pokedexNames(st))
+	}
+	if vaporeon.Level != 10 || len(vaporeon.History) != 1 || vaporeon.History[0].Description != "evolved from eevee into vaporeon at level 10" {
+		t.Errorf("expected the evolution in the history, got %+v", vaporeon)
+	}
+
+	out.Reset()
+	st.pc.Pokemon[0].Level = 30
+	st.pc.Pokemon[0].Nickname = "jelly"
+	runScript(st, strings.NewReader("evolve jelly\ninspect tentacruel\n"), true)
+	if !strings.Contains(out.String(), "Your jelly evolved into tentacruel!") || !strings.Contains(out.String(), "Nickname: jelly\n") {
+		t.Errorf("expected tentacool to evolve at level 30, got:\n%s", out.String())
+	}
+}
+
+func TestPCCommands(t *testing.T) {
+	st, out := newTestState(t)
+	st.errOut = out
+	st.trainer.Bag = trainer.Bag{"master-ball": 2}
+	runScript(st, strings.NewReader("goto pastoria-city-area\nwalk\ncatch --ball master\nwalk\ncatch --ball master\n"), false)
+	out.Reset()
+
+	script := strings.Join([]string{
+		"inspect tentacool",
+		"nickname tentacool jelly",
+		"nickname 2 jelly",
+		"nickname #1 jelly",
+		"nickname 1 42",
+		"nickname 1 Staryu",
+		"nickname 1 Tentacool",
+		"pc",
+		"release jely",
+		"release jelly",
+		"release #2",
+		"pc",
+		"pokedex",
+	}, "\n")
+	runScript(st, strings.NewReader(script), true)
+
+	expected := `Error executing inspect command: you have more than one tentacool, pick one by id: #1, #2
+Error executing nickname command: you have more than one tentacool, pick one by id: #1, #2
+tentacool is now called jelly
+Error executing nickname command: #2 is already called jelly
+Error executing nickname command: nicknames cannot be a number or start with #
+Error executing nickname command: Staryu is the name of a Pokemon, pick another nickname
+Error executing nickname command: Tentacool is the name of a Pokemon, pick another nickname
+Your PC:
+  #1 tentacool, lv 20, male
+  #2 jelly (tentacool), lv 20, male
+Error executing release command: jely is not in your pokedex, did you mean "jelly"?
+jelly (#2) was released. Bye-bye, jelly!
+Error executing release command: #2 is not in your pokedex
+Your PC:
+  #1 tentacool, lv 20, male
+Your Pokedex:
+  - tentacool
+`
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+
+	out.Reset()
+	runScript(st, strings.NewReader("inspect 1\n"), true)
+	for _, line := range []string{"ID: #1\n", "Level: 20\nXP: 10000\n", "Gender: male\n", "in pastoria-city-area\n", "IVs:\n", "EVs:\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected inspect to show %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestMergeEncounterRows(t *testing.T) {
+	surf := pokeapi.NamedAPIResource{Name: "surf"}
+	rod := pokeapi.NamedAPIResource{Name: "old-rod"}
+	swarm := []pokeapi.NamedAPIResource{{Name: "swarm-no"}}
+	details := []pokeapi.Encounter{
+		{Method: surf, Chance: 60, MinLevel: 20, MaxLevel: 30},
+		{Method: rod, Chance: 70, MinLevel: 3, MaxLevel: 5},
+		{Method: surf, Chance: 30, MinLevel: 15, MaxLevel: 20},
+		{Method: surf, Chance: 10, MinLevel: 35, MaxLevel: 40, ConditionValues: swarm},
+		{Method: surf, Chance: 10, MinLevel: 30, MaxLevel: 35},
+	}
+	expected := []encounterRow{
+		{Method: "surf", Chance: 100, MinLevel: 15, MaxLevel: 35, Conditions: []string{}},
+		{Method: "old-rod", Chance: 70, MinLevel: 3, MaxLevel: 5, Conditions: []string{}},
+		{Method: "surf", Chance: 10, MinLevel: 35, MaxLevel: 40, Conditions: []string{"swarm-no"}},
+	}
+	actual := mergeEncounterRows(details)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected: %+v, Got: %+v", expected, actual)
+	}
+}
+
+func TestCatchAttemptsArePerWildPokemon(t *testing.T) {
+	st, out := newTestState(t)
+	st.trainer.Bag = trainer.Bag{"poke-ball": 50}
+	runScript(st, strings.NewReader("goto pastoria-city-area\nwalk\n"), false)
+	for st.trainer.Wild != nil && st.trainer.Wild.Attempts < 2 {
+		out.Reset()
+		runScript(st, strings.NewReader("catch\n"), false)
+		if st.trainer.Wild == nil {
+			runScript(st, strings.NewReader("walk\n"), false)
+		}
+	}
+	if !strings.Contains(out.String(), "Attempted catch: 2\n") {
+		t.Errorf("expected the second escape to be counted, got:\n%s", out.String())
+	}
+
+	runScript(st, strings.NewReader("walk\n"), false)
+	if st.trainer.Wild.Attempts != 0 {
+		t.Errorf("expected a new wild Pokemon to start with no attempts, got %v", st.trainer.Wild.Attempts)
+	}
+}
+
+func TestBattleCommand(t *testing.T) {
+	st, out := newTestState(t)
+	st.errOut = out
+	st.pc.Add(trainer.Entry{Pokemon: pokeapi.Pokemon{Name: "tentacool"}, Level: 29, XP: 33700})
+	runScript(st, strings.NewReader("goto pastoria-city-area\n"), false)
+	out.Reset()
+
+	script := "battle tentacool\nwalk\nbattle tentacol\nbattle tentacool\nevolve tentacool\n"
+	runScript(st, strings.NewReader(script), true)
+
+	output := out.String()
+	for _, expected := range []string{
+		"Error executing battle command: there is no wild Pokemon around, use 'walk' to look for one\n",
+		"Error executing battle command: tentacol is not in your pokedex, did you mean \"tentacool\"?\n",
+		"tentacool defeated the wild tentacool and gained 191 XP!\ntentacool grew to level 30!\n",
+		"Congratulations! Your tentacool evolved into tentacruel!\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q, got:\n%s", expected, output)
+		}
+	}
+	if st.trainer.Wild != nil {
+		t.Errorf("expected the wild Pokemon to be gone after the battle")
+	}
+	if entry := st.pc.Pokemon[0]; entry.EVs["hp"] != 1 || len(entry.History) != 2 {
+		t.Errorf("expected an hp EV and the level and evolution in the history, got %+v", entry)
+	}
+}
+
+func TestLoadSaveKeepsNewerSave(t *testing.T) {
+	st, _ := newTestState(t)
+	var errOut bytes.Buffer
+	st.errOut = &errOut
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "pokedexcli", "save.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"version": 999}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	st.loadSave()
+	if st.savePath != "" {
+		t.Errorf("expected a newer save not to be written to, got save path %q", st.savePath)
+	}
+	if body, err := os.ReadFile(path); err != nil || string(body) != `{"version": 999}` {
+		t.Errorf("expected the newer save to be left in place, got %q, %v", body, err)
+	}
+	if !strings.Contains(errOut.String(), "your pokedex will not be saved") {
+		t.Errorf("expected a warning, got %q", errOut.String())
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+	"github.com/thmastin/pokedexcli/internal/trainer"
+)
+
+func commandEvolution(st *state, args commandArgs) (result, error) {
+	name := args.arg(0)
+	// A nickname is looked up as the species of that Pokemon.
+	if entry, err := st.pc.Find(name); err == nil {
+		name = entry.Species()
+	}
+	chain, err := st.evolutionChain(name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, didYouMean(err, name, st.pokemonCandidates())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return evolutionResult{Chain: evolutionTree(chain.Chain, st.pc.Species())}, nil
+}
+
+func commandEvolve(st *state, args commandArgs) (result, error) {
+	name := args.arg(0)
+	entry, err := st.pc.Find(name)
+	if errors.Is(err, trainer.ErrNotCaught) {
+		return nil, didYouMean(err, name, pokedexNames(st))
+	}
+	if err != nil {
+		return nil, err
+	}
+	species := entry.Species()
+	chain, err := st.evolutionChain(species)
+	if err != nil {
+		return nil, err
+	}
+	link, ok := trainer.FindLink(chain.Chain, species)
+	if !ok || len(link.EvolvesTo) == 0 {
+		return nil, fmt.Errorf("%s does not evolve any further", entry.Name())
+	}
+	next, err := pickEvolution(entry.Name(), link, args.flag("into"))
+	if err != nil {
+		return nil, err
+	}
+	evolution := trainer.Evolution{Item: args.flag("item"), Trade: args.hasFlag("trade"), Time: time.Now()}
+	if err := trainer.CanEvolve(*entry, next, evolution); err != nil {
+		return nil, err
+	}
+
+	evolved, err := st.client.Pokemon(next.Species.Name)
+	if err != nil {
+		return nil, err
+	}
+	from := entry.Name()
+	entry.Evolve(evolved)
+	st.savePC()
+	return evolveResult{From: from, To: evolved.Name, Level: entry.Level}, nil
+}
+
+// evolutionChain fetches the chain a species belongs to.
+func (st *state) evolutionChain(species string) (pokeapi.EvolutionChain, error) {
+	res, err := st.client.Species(species)
+	if err != nil {
+		return pokeapi.EvolutionChain{}, err
+	}
+	if res.EvolutionChain.URL == "" {
+		return pokeapi.EvolutionChain{}, fmt.Errorf("%s has no evolution chain", res.Name)
+	}
+	return st.client.EvolutionChain(res.EvolutionChain.URL)
+}
+
+// pickEvolution chooses the next stage named by into, which may be left
+// out when there is only one.
+func pickEvolution(name string, link pokeapi.ChainLink, into string) (pokeapi.ChainLink, error) {
+	options := []string{}
+	for _, next := range link.EvolvesTo {
+		if next.Species.Name == into || (into == "" && len(link.EvolvesTo) == 1) {
+			return next, nil
+		}
+		options = append(options, next.Species.Name)
+	}
+	if into == "" {
+		return pokeapi.ChainLink{}, fmt.Errorf("%s can evolve into %s, pick one with --into", name, strings.Join(options, ", "))
+	}
+	return pokeapi.ChainLink{}, didYouMean(fmt.Errorf("%s cannot evolve into %s, only into %s", name, into, strings.Join(options, ", ")), into, options)
+}
+
+func evolutionTree(link pokeapi.ChainLink, caught map[string]bool) evolutionNode {
+	node := evolutionNode{
+		Species:    link.Species.Name,
+		Caught:     caught[link.Species.Name],
+		Conditions: []string{},
+		EvolvesTo:  []evolutionNode{},
+	}
+	for _, detail := range link.EvolutionDetails {
+		node.Conditions = append(node.Conditions, describeEvolution(detail))
+	}
+	for _, next := range link.EvolvesTo {
+		node.EvolvesTo = append(node.EvolvesTo, evolutionTree(next, caught))
+	}
+	return node
+}
+
+// describeEvolution puts one way of evolving into words, such as
+// "level 16" or "level up, happiness 160, during the day".
+func describeEvolution(detail pokeapi.EvolutionDetail) string {
+	parts := []string{}
+	switch detail.Trigger.Name {
+	case "level-up":
+		if detail.MinLevel != nil {
+			parts = append(parts, fmt.Sprintf("level %d", *detail.MinLevel))
+		} else {
+			parts = append(parts, "level up")
+		}
+	case "use-item":
+		if detail.Item != nil {
+			parts = append(parts, "use "+detail.Item.Name)
+		} else {
+			parts = append(parts, "use an item")
+		}
+	case "trade":
+		parts = append(parts, "trade")
+	default:
+		parts = append(parts, strings.ReplaceAll(detail.Trigger.Name, "-", " "))
+		if detail.MinLevel != nil {
+			parts = append(parts, fmt.Sprintf("level %d", *detail.MinLevel))
+		}
+	}
+
+	if detail.HeldItem != nil {
+		parts = append(parts, "holding "+detail.HeldItem.Name)
+	}
+	if detail.TradeSpecies != nil {
+		parts = append(parts, "for "+detail.TradeSpecies.Name)
+	}
+	if detail.KnownMove != nil {
+		parts = append(parts, "knowing "+detail.KnownMove.Name)
+	}
+	if detail.KnownMoveType != nil {
+		parts = append(parts, "knowing a "+detail.KnownMoveType.Name+" move")
+	}
+	if detail.Location != nil {
+		parts = append(parts, "at "+detail.Location.Name)
+	}
+	if detail.MinHappiness != nil {
+		parts = append(parts, fmt.Sprintf("happiness %d", *detail.MinHappiness))
+	}
+	if detail.MinAffection != nil {
+		parts = append(parts, fmt.Sprintf("affection %d", *detail.MinAffection))
+	}
+	if detail.MinBeauty != nil {
+		parts = append(parts, fmt.Sprintf("beauty %d", *detail.MinBeauty))
+	}
+	if detail.TimeOfDay != "" {
+		parts = append(parts, "during the "+detail.TimeOfDay)
+	}
+	if detail.PartySpecies != nil {
+		parts = append(parts, "with "+detail.PartySpecies.Name+" in the party")
+	}
+	if detail.PartyType != nil {
+		parts = append(parts, "with a "+detail.PartyType.Name+" Pokemon in the party")
+	}
+	if detail.RelativePhysicalStats != nil {
+		switch *detail.RelativePhysicalStats {
+		case 1:
+			parts = append(parts, "attack above defense")
+		case 0:
+			parts = append(parts, "attack equal to defense")
+		case -1:
+			parts = append(parts, "attack below defense")
+		}
+	}
+	if detail.Gender != nil {
+		switch *detail.Gender {
+		case 1:
+			parts = append(parts, "female")
+		case 2:
+			parts = append(parts, "male")
+		}
+	}
+	if detail.NeedsOverworldRain {
+		parts = append(parts, "in the rain")
+	}
+	if detail.TurnUpsideDown {
+		parts = append(parts, "holding the console upside down")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,49 @@
+package fuzzy
+
+// Distance is the optimal string alignment distance between a and b: the
+// number of insertions, deletions, substitutions and adjacent
+// transpositions needed to turn one into the other.
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
+
+// MaxDistance is how far a candidate may be from word and still count as
+// a plausible typo of it.
+func MaxDistance(word string) int {
+	return len([]rune(word))/3 + 1
+}
+
+// Closest returns the candidate nearest to word, preferring the earliest
+// candidate on ties. ok is false when nothing is within MaxDistance.
+func Closest(word string, candidates []string) (match string, ok bool) {
+	best := MaxDistance(word) + 1
+	for _, candidate := range candidates {
+		if d := Distance(word, candidate); d < best {
+			best = d
+			match = candidate
+			ok = true
+		}
+	}
+	return match, ok
+}
@@ -0,0 +1,48 @@
+package fuzzy
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "map", b: "", expected: 3},
+		{a: "pikachu", b: "pikachu", expected: 0},
+		{a: "pikchu", b: "pikachu", expected: 1},
+		{a: "ctach", b: "catch", expected: 1},
+		{a: "kitten", b: "sitting", expected: 3},
+	}
+	for _, c := range cases {
+		t.Run(c.a+"->"+c.b, func(t *testing.T) {
+			if actual := Distance(c.a, c.b); actual != c.expected {
+				t.Errorf("Expected: %v, Got: %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"map", "mapb", "explore", "catch", "inspect", "pokedex"}
+	cases := []struct {
+		word     string
+		expected string
+		ok       bool
+	}{
+		{word: "mpa", expected: "map", ok: true},
+		{word: "explor", expected: "explore", ok: true},
+		{word: "pokdex", expected: "pokedex", ok: true},
+		{word: "teleport", expected: "", ok: false},
+		{word: "map", expected: "map", ok: true},
+	}
+	for _, c := range cases {
+		t.Run(c.word, func(t *testing.T) {
+			actual, ok := Closest(c.word, candidates)
+			if actual != c.expected || ok != c.ok {
+				t.Errorf("Expected: %v %v, Got: %v %v", c.expected, c.ok, actual, ok)
+			}
+		})
+	}
+}
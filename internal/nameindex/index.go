@@ -0,0 +1,128 @@
+package nameindex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/fuzzy"
+)
+
+// New creates an index that loads names from source on first use and
+// reloads them once they are older than maxAge.
+func New(source Source, maxAge time.Duration) *Index {
+	return &Index{
+		source: source,
+		maxAge: maxAge,
+		lists:  make(map[Kind]nameList),
+	}
+}
+
+func ParseKind(name string) (Kind, error) {
+	switch name {
+	case "pokemon":
+		return Pokemon, nil
+	case "area", "areas", "location-area":
+		return Area, nil
+	case "location", "locations":
+		return Location, nil
+	case "region", "regions":
+		return Region, nil
+	default:
+		return "", fmt.Errorf("unknown kind: %s, use pokemon, area, location or region", name)
+	}
+}
+
+// Names returns every name of a kind in alphabetical order. The lock is
+// not held while names are fetched, so Loaded never waits on the network.
+func (idx *Index) Names(kind Kind) ([]string, error) {
+	idx.mu.Lock()
+	list, ok := idx.lists[kind]
+	idx.mu.Unlock()
+	if ok && time.Since(list.fetchedAt) <= idx.maxAge {
+		return list.names, nil
+	}
+	endpoint, known := endpoints[kind]
+	if !known {
+		return nil, fmt.Errorf("unknown kind: %s", kind)
+	}
+	names, err := idx.source(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s index: %w", kind, err)
+	}
+	names = append([]string{}, names...)
+	sort.Strings(names)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.lists[kind] = nameList{names: names, fetchedAt: time.Now()}
+	return names, nil
+}
+
+// Loaded returns the names of a kind that have already been loaded, even
+// if they are older than maxAge. It never fetches.
+func (idx *Index) Loaded(kind Kind) []string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.lists[kind].names
+}
+
+// Search finds names of the given kinds that start with, contain, or are a
+// plausible typo of term. Results are ordered by match quality, then by
+// edit distance and name, and at most limit are returned.
+func (idx *Index) Search(term string, kinds []Kind, limit int) ([]Match, error) {
+	term = strings.ToLower(strings.TrimSpace(term))
+	matches := []Match{}
+	for _, kind := range kinds {
+		names, err := idx.Names(kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if match, ok := matchName(term, name); ok {
+				match.Kind = kind
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	rank := map[MatchMode]int{MatchExact: 0, MatchPrefix: 1, MatchSubstring: 2, MatchFuzzy: 3}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if rank[a.Mode] != rank[b.Mode] {
+			return rank[a.Mode] < rank[b.Mode]
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Name < b.Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func matchName(term, name string) (Match, bool) {
+	distance := fuzzy.Distance(term, name)
+	// Compare against the start of long names too, so a typo in the first
+	// word of "canalave-city-area" still matches.
+	if runes := []rune(name); len(runes) > len([]rune(term)) {
+		distance = min(distance, fuzzy.Distance(term, string(runes[:len([]rune(term))])))
+	}
+	match := Match{Name: name, Distance: distance}
+	switch {
+	case term == name:
+		match.Mode = MatchExact
+	case strings.HasPrefix(name, term):
+		match.Mode = MatchPrefix
+	case strings.Contains(name, term):
+		match.Mode = MatchSubstring
+	case distance <= fuzzy.MaxDistance(term):
+		match.Mode = MatchFuzzy
+	default:
+		return Match{}, false
+	}
+	return match, true
+}
@@ -0,0 +1,142 @@
+package nameindex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestIndex(calls *int) *Index {
+	return New(func(endpoint string) ([]string, error) {
+		*calls++
+		switch endpoint {
+		case "pokemon":
+			return []string{"pikachu", "raichu", "pichu", "bulbasaur", "mr-mime"}, nil
+		case "location-area":
+			return []string{"canalave-city-area", "eterna-forest-area", "pallet-town-area"}, nil
+		}
+		return nil, errors.New("unexpected endpoint")
+	}, time.Hour)
+}
+
+func TestSearch(t *testing.T) {
+	calls := 0
+	idx := newTestIndex(&calls)
+	cases := []struct {
+		name     string
+		term     string
+		kinds    []Kind
+		expected []string
+		modes    []MatchMode
+	}{
+		{
+			name:     "prefix before substring",
+			term:     "pi",
+			kinds:    []Kind{Pokemon},
+			expected: []string{"pichu", "pikachu"},
+			modes:    []MatchMode{MatchPrefix, MatchPrefix},
+		},
+		{
+			name:     "substring",
+			term:     "chu",
+			kinds:    []Kind{Pokemon},
+			expected: []string{"pichu", "pikachu", "raichu"},
+			modes:    []MatchMode{MatchSubstring, MatchSubstring, MatchSubstring},
+		},
+		{
+			name:     "fuzzy",
+			term:     "bulbsaur",
+			kinds:    []Kind{Pokemon},
+			expected: []string{"bulbasaur"},
+			modes:    []MatchMode{MatchFuzzy},
+		},
+		{
+			name:     "fuzzy on the start of an area",
+			term:     "canalve",
+			kinds:    []Kind{Area},
+			expected: []string{"canalave-city-area"},
+			modes:    []MatchMode{MatchFuzzy},
+		},
+		{
+			name:     "exact first across kinds",
+			term:     "pallet-town-area",
+			kinds:    Kinds,
+			expected: []string{"pallet-town-area"},
+			modes:    []MatchMode{MatchExact},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			matches, err := idx.Search(c.term, c.kinds, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(matches) != len(c.expected) {
+				t.Fatalf("Expected: %v, Got: %v", c.expected, matches)
+			}
+			for i, match := range matches {
+				if match.Name != c.expected[i] || match.Mode != c.modes[i] {
+					t.Errorf("Expected: %v (%v), Got: %v (%v)", c.expected[i], c.modes[i], match.Name, match.Mode)
+				}
+			}
+		})
+	}
+	if calls != 2 {
+		t.Errorf("expected each listing to be fetched once, got %v calls", calls)
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	calls := 0
+	idx := newTestIndex(&calls)
+	matches, err := idx.Search("a", Kinds, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("Expected 2 matches, Got: %v", len(matches))
+	}
+}
+
+func TestNamesRefreshWhenStale(t *testing.T) {
+	calls := 0
+	idx := newTestIndex(&calls)
+	idx.maxAge = 5 * time.Millisecond
+
+	if _, err := idx.Names(Pokemon); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := idx.Names(Pokemon); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, Got: %v", calls)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := idx.Names(Pokemon); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, Got: %v", calls)
+	}
+}
+
+func TestLoadedNeverFetches(t *testing.T) {
+	calls := 0
+	idx := newTestIndex(&calls)
+
+	if names := idx.Loaded(Area); len(names) != 0 {
+		t.Errorf("Expected no names before loading, Got: %v", names)
+	}
+	if _, err := idx.Names(Area); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	idx.maxAge = 0
+	if names := idx.Loaded(Area); len(names) != 3 {
+		t.Errorf("Expected 3 names, Got: %v", names)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, Got: %v", calls)
+	}
+}
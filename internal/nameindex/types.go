@@ -0,0 +1,61 @@
+package nameindex
+
+import (
+	"sync"
+	"time"
+)
+
+// Kind is a category of named PokeAPI resources held in the index.
+type Kind string
+
+const (
+	Pokemon  Kind = "pokemon"
+	Area     Kind = "area"
+	Location Kind = "location"
+	Region   Kind = "region"
+)
+
+// Kinds lists the kinds searched when none is given, in the order search
+// results are grouped by. Locations and regions are only searched on
+// request.
+var Kinds = []Kind{Pokemon, Area}
+
+// endpoints maps each kind to the PokeAPI listing it is built from.
+var endpoints = map[Kind]string{
+	Pokemon:  "pokemon",
+	Area:     "location-area",
+	Location: "location",
+	Region:   "region",
+}
+
+// Source lists every resource name of a PokeAPI endpoint.
+type Source func(endpoint string) ([]string, error)
+
+type Index struct {
+	source Source
+	maxAge time.Duration
+	mu     sync.Mutex
+	lists  map[Kind]nameList
+}
+
+type nameList struct {
+	names     []string
+	fetchedAt time.Time
+}
+
+// MatchMode says how a name matched a search term, from best to worst.
+type MatchMode string
+
+const (
+	MatchExact     MatchMode = "exact"
+	MatchPrefix    MatchMode = "prefix"
+	MatchSubstring MatchMode = "substring"
+	MatchFuzzy     MatchMode = "fuzzy"
+)
+
+type Match struct {
+	Name     string    `json:"name"`
+	Kind     Kind      `json:"kind"`
+	Mode     MatchMode `json:"match"`
+	Distance int       `json:"distance"`
+}
@@ -1,74 +1,269 @@
 package pokeapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokecache"
 )
 
-func FetchLocationAreas(url string) (LocationAreaResponse, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return LocationAreaResponse{}, fmt.Errorf("failed to get response: %w", err)
+const (
+	DefaultBaseURL   = "https://pokeapi.co/api/v2/"
+	DefaultTimeout   = 10 * time.Second
+	DefaultUserAgent = "pokedexcli"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	cache      *pokecache.Cache
+	prefetch   *prefetcher
+	offlineDir string
+	recordDir  string
+}
+
+// ErrNotFound is returned, possibly wrapped, when PokeAPI responds with 404.
+var ErrNotFound = errors.New("not found")
+
+// notFoundError gives ErrNotFound a message meant for the user.
+type notFoundError struct {
+	msg string
+}
+
+func (e notFoundError) Error() string {
+	return e.msg
+}
+
+func (e notFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+func NewClient(opts ...Option) *Client {
+	client := &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: &http.Client{},
+		userAgent:  DefaultUserAgent,
 	}
-	defer resp.Body.Close()
+	for _, opt := range opts {
+		opt(client)
+	}
+	httpClient := *client.httpClient
+	if client.timeout != 0 {
+		httpClient.Timeout = client.timeout
+	} else if httpClient.Timeout == 0 {
+		httpClient.Timeout = DefaultTimeout
+	}
+	if client.offlineDir != "" {
+		httpClient.Transport = fixtureTransport{dir: client.offlineDir}
+	} else if client.recordDir != "" {
+		next := httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		httpClient.Transport = recordingTransport{dir: client.recordDir, next: next}
+	}
+	client.httpClient = &httpClient
+	return client
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return LocationAreaResponse{}, fmt.Errorf("failed to read body: %w", err)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/") + "/"
 	}
+}
 
-	var res LocationAreaResponse
-	if err := json.Unmarshal(body, &res); err != nil {
-		return LocationAreaResponse{}, fmt.Errorf("failed to unmarshal: %w", err)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
 	}
-	return res, nil
 }
 
-func FetchEncounter(url string, areaName string) (EncounterResponse, error) {
+// WithTimeout limits how long a request may take. Without it a client
+// given to WithHTTPClient keeps its own Timeout, and DefaultTimeout is used
+// if it has none.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return EncounterResponse{}, fmt.Errorf("failed to get response %w", err)
+// WithCache stores raw response bodies in cache, keyed by request URL.
+func WithCache(cache *pokecache.Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode == 404 {
-		return EncounterResponse{}, fmt.Errorf("invalid area: %v. please use the pokedex 'map' command to see valid areas", areaName)
+// WithOffline serves every response from the fixture bundle in dir instead
+// of the network.
+func WithOffline(dir string) Option {
+	return func(c *Client) {
+		c.offlineDir = dir
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return EncounterResponse{}, fmt.Errorf("failed to read body: %w", err)
+// WithRecording saves every live response into the fixture bundle in dir.
+func WithRecording(dir string) Option {
+	return func(c *Client) {
+		c.recordDir = dir
 	}
+}
 
-	var res EncounterResponse
-	if err := json.Unmarshal(body, &res); err != nil {
-		return EncounterResponse{}, fmt.Errorf("failed to unmarshal: %w", err)
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
 	}
-	return res, nil
 }
 
-func FetchPokemon(url string, pokemonName string) (Pokemon, error) {
-	resp, err := http.Get(url)
+// EndpointURL returns the URL of an endpoint such as "pokemon", ending in
+// a slash so a resource name can be appended.
+func (c *Client) EndpointURL(endpoint string) string {
+	return c.baseURL + endpoint + "/"
+}
+
+func (c *Client) fetch(ctx context.Context, url string) ([]byte, error) {
+	if c.cache != nil {
+		if body, found := c.cache.Get(url); found {
+			return body, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return Pokemon{}, fmt.Errorf("failed to get response %w", err)
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 404 {
-		return Pokemon{}, fmt.Errorf("invalid pokemon: %v. please use the pokedex 'explore' command to see valid pokemon", pokemonName)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %v from %s", resp.StatusCode, url)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return Pokemon{}, fmt.Errorf("failed to read body: %w", err)
+		return nil, fmt.Errorf("failed to read body: %w", err)
 	}
+	if c.cache != nil {
+		c.cache.Add(url, body)
+	}
+	return body, nil
+}
 
-	var res Pokemon
+// get fetches url through the cache and decodes the body into T. Every
+// endpoint method goes through here so new endpoints are cached for free.
+func get[T any](c *Client, url string) (T, error) {
+	var res T
+	body, err := c.fetch(context.Background(), url)
+	if err != nil {
+		return res, err
+	}
 	if err := json.Unmarshal(body, &res); err != nil {
-		return Pokemon{}, fmt.Errorf("failed to unmarshal %w", err)
+		return res, fmt.Errorf("failed to unmarshal: %w", err)
 	}
 	return res, nil
 }
+
+// PageURL is the URL of one page of a listing endpoint.
+func (c *Client) PageURL(endpoint string, offset, limit int) string {
+	return fmt.Sprintf("%s?offset=%d&limit=%d", c.EndpointURL(endpoint), offset, limit)
+}
+
+// List fetches one page of any listing endpoint, such as a Next URL.
+func (c *Client) List(url string) (NamedAPIResourceList, error) {
+	return get[NamedAPIResourceList](c, url)
+}
+
+func (c *Client) ListPage(endpoint string, offset, limit int) (NamedAPIResourceList, error) {
+	return c.List(c.PageURL(endpoint, offset, limit))
+}
+
+func (c *Client) Encounter(areaName string) (EncounterResponse, error) {
+	res, err := get[EncounterResponse](c, c.EndpointURL("location-area")+areaName)
+	if errors.Is(err, ErrNotFound) {
+		return res, notFoundError{fmt.Sprintf("invalid area: %v. please use the pokedex 'map' command to see valid areas", areaName)}
+	}
+	return res, err
+}
+
+func (c *Client) Region(regionName string) (Region, error) {
+	res, err := get[Region](c, c.EndpointURL("region")+regionName)
+	if errors.Is(err, ErrNotFound) {
+		return res, notFoundError{fmt.Sprintf("invalid region: %v. please use the pokedex 'regions' command to see valid regions", regionName)}
+	}
+	return res, err
+}
+
+func (c *Client) Location(locationName string) (Location, error) {
+	res, err := get[Location](c, c.EndpointURL("location")+locationName)
+	if errors.Is(err, ErrNotFound) {
+		return res, notFoundError{fmt.Sprintf("invalid location: %v. please use the pokedex 'region' command to see valid locations", locationName)}
+	}
+	return res, err
+}
+
+func (c *Client) Pokemon(pokemonName string) (Pokemon, error) {
+	res, err := get[Pokemon](c, c.EndpointURL("pokemon")+pokemonName)
+	if errors.Is(err, ErrNotFound) {
+		return res, notFoundError{fmt.Sprintf("invalid pokemon: %v. please use the pokedex 'explore' command to see valid pokemon", pokemonName)}
+	}
+	return res, err
+}
+
+func (c *Client) Species(speciesName string) (PokemonSpecies, error) {
+	res, err := get[PokemonSpecies](c, c.EndpointURL("pokemon-species")+speciesName)
+	if errors.Is(err, ErrNotFound) {
+		return res, notFoundError{fmt.Sprintf("invalid species: %v. please use the pokedex 'search' command to find valid pokemon", speciesName)}
+	}
+	return res, err
+}
+
+// EvolutionChain fetches a chain from the URL a species links to.
+func (c *Client) EvolutionChain(url string) (EvolutionChain, error) {
+	return get[EvolutionChain](c, url)
+}
+
+// PokemonEncounters lists every location area where a Pokemon can be met
+// in the wild.
+func (c *Client) PokemonEncounters(pokemonName string) ([]LocationAreaEncounter, error) {
+	res, err := get[[]LocationAreaEncounter](c, c.EndpointURL("pokemon")+pokemonName+"/encounters")
+	if errors.Is(err, ErrNotFound) {
+		return res, notFoundError{fmt.Sprintf("invalid pokemon: %v. please use the pokedex 'search' command to find valid pokemon", pokemonName)}
+	}
+	return res, err
+}
+
+// allResourcesLimit is larger than any PokeAPI listing, so a single page
+// holds every resource of an endpoint.
+const allResourcesLimit = 100000
+
+// ResourceNames lists the name of every resource of an endpoint, such as
+// every Pokemon species or every location area.
+func (c *Client) ResourceNames(endpoint string) ([]string, error) {
+	res, err := get[NamedAPIResourceList](c, fmt.Sprintf("%s?limit=%d", c.EndpointURL(endpoint), allResourcesLimit))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(res.Results))
+	for _, resource := range res.Results {
+		names = append(names, resource.Name)
+	}
+	return names, nil
+}
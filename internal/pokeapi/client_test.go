@@ -0,0 +1,267 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokecache"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientPokemon(t *testing.T) {
+	var gotPath, gotAgent string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAgent = r.Header.Get("User-Agent")
+		if r.URL.Path != "/api/v2/pokemon/pikachu" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id": 25, "name": "pikachu", "base_experience": 112}`))
+	})
+	client := NewClient(WithBaseURL(server.URL+"/api/v2"), WithUserAgent("pokedex-test"))
+
+	pokemon, err := client.Pokemon("pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.Name != "pikachu" || pokemon.BaseExperience != 112 {
+		t.Errorf("unexpected pokemon: %+v", pokemon)
+	}
+	if gotPath != "/api/v2/pokemon/pikachu" {
+		t.Errorf("Expected path: /api/v2/pokemon/pikachu, Got: %v", gotPath)
+	}
+	if gotAgent != "pokedex-test" {
+		t.Errorf("Expected user agent: pokedex-test, Got: %v", gotAgent)
+	}
+
+	_, err = client.Pokemon("missingno")
+	if err == nil || !strings.Contains(err.Error(), "invalid pokemon: missingno") {
+		t.Errorf("expected invalid pokemon error, got %v", err)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	})
+	client := NewClient(WithBaseURL(server.URL), WithTimeout(5*time.Millisecond))
+
+	_, err := client.Encounter("canalave-city-area")
+	if err == nil {
+		t.Errorf("expected timeout error")
+	}
+}
+
+func TestClientTimeoutPrecedence(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     []Option
+		expected time.Duration
+	}{
+		{
+			name:     "default",
+			expected: DefaultTimeout,
+		},
+		{
+			name:     "injected client keeps its timeout",
+			opts:     []Option{WithHTTPClient(&http.Client{Timeout: time.Minute})},
+			expected: time.Minute,
+		},
+		{
+			name:     "injected client without a timeout",
+			opts:     []Option{WithHTTPClient(&http.Client{})},
+			expected: DefaultTimeout,
+		},
+		{
+			name:     "explicit timeout wins",
+			opts:     []Option{WithHTTPClient(&http.Client{Timeout: time.Minute}), WithTimeout(time.Second)},
+			expected: time.Second,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := NewClient(c.opts...)
+			if client.httpClient.Timeout != c.expected {
+				t.Errorf("Expected: %v, Got: %v", c.expected, client.httpClient.Timeout)
+			}
+		})
+	}
+}
+
+func TestEndpointURL(t *testing.T) {
+	client := NewClient(WithBaseURL("http://localhost:8080/api/v2/"))
+	expected := "http://localhost:8080/api/v2/location-area/"
+	if actual := client.EndpointURL("location-area"); actual != expected {
+		t.Errorf("Expected: %v, Got: %v", expected, actual)
+	}
+}
+
+func TestClientCachesResponses(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/location-area/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name": "canalave-city-area", "pokemon_encounters": [{"pokemon": {"name": "tentacool"}}]}`))
+	})
+	client := NewClient(WithBaseURL(server.URL), WithCache(pokecache.NewCache(time.Minute)))
+
+	for i := 0; i < 2; i++ {
+		encounter, err := client.Encounter("canalave-city-area")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(encounter.PokemonEncounters) != 1 {
+			t.Errorf("Expected 1 encounter, Got: %v", len(encounter.PokemonEncounters))
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, Got: %v", requests)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Encounter("missing")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected not found responses to skip the cache, got %v requests", requests)
+	}
+}
+
+func TestRecordThenReplayOffline(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/location-area/":
+			w.Write([]byte(`{"count": 1, "results": [{"name": "canalave-city-area"}]}`))
+		case "/api/v2/pokemon/pikachu":
+			w.Write([]byte(`{"id": 25, "name": "pikachu"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	dir := t.TempDir()
+
+	recorder := NewClient(WithBaseURL(server.URL+"/api/v2"), WithRecording(dir))
+	if _, err := recorder.Pokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pageURL := recorder.EndpointURL("location-area") + "?offset=20&limit=20"
+	if _, err := recorder.List(pageURL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.Close()
+
+	offline := NewClient(WithBaseURL("https://pokeapi.invalid/api/v2"), WithOffline(dir))
+	pokemon, err := offline.Pokemon("pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pokemon.ID != 25 {
+		t.Errorf("Expected: 25, Got: %v", pokemon.ID)
+	}
+	areas, err := offline.ListPage("location-area", 20, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(areas.Results) != 1 {
+		t.Errorf("Expected 1 area, Got: %v", len(areas.Results))
+	}
+	_, err = offline.Pokemon("bulbasaur")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected missing fixture to be not found, got %v", err)
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.Write([]byte(`{"name": "pikachu"}`))
+	})
+	client := NewClient(WithBaseURL(server.URL), WithCache(pokecache.NewCache(time.Minute)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.StartPrefetch(ctx, 2)
+
+	url := client.EndpointURL("pokemon") + "pikachu"
+	client.Prefetch(url, url, client.EndpointURL("pokemon")+"raichu")
+	client.WaitPrefetch()
+	if _, err := client.Pokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/pokemon/pikachu"] != 1 {
+		t.Errorf("Expected 1 request for pikachu, Got: %v", requests["/pokemon/pikachu"])
+	}
+	if requests["/pokemon/raichu"] != 1 {
+		t.Errorf("Expected 1 request for raichu, Got: %v", requests["/pokemon/raichu"])
+	}
+}
+
+func TestPrefetchStopsWhenCancelled(t *testing.T) {
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+	client := NewClient(WithBaseURL(server.URL), WithCache(pokecache.NewCache(time.Minute)))
+	ctx, cancel := context.WithCancel(context.Background())
+	client.StartPrefetch(ctx, 2)
+	cancel()
+
+	client.Prefetch(client.EndpointURL("pokemon") + "pikachu")
+	time.Sleep(10 * time.Millisecond)
+	if requests != 0 {
+		t.Errorf("Expected no requests after cancel, Got: %v", requests)
+	}
+}
+
+func TestWaitPrefetchAfterCancelWithQueuedJobs(t *testing.T) {
+	started := make(chan struct{}, 1)
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	})
+	client := NewClient(WithBaseURL(server.URL), WithCache(pokecache.NewCache(time.Minute)))
+	ctx, cancel := context.WithCancel(context.Background())
+	client.StartPrefetch(ctx, 1)
+
+	client.Prefetch(client.EndpointURL("pokemon")+"pikachu", client.EndpointURL("pokemon")+"raichu", client.EndpointURL("pokemon")+"pichu")
+	<-started
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		client.WaitPrefetch()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitPrefetch did not return after cancel")
+	}
+	if len(client.prefetch.pending) != 0 {
+		t.Errorf("expected no pending URLs, got %v", client.prefetch.pending)
+	}
+}
@@ -0,0 +1,82 @@
+package pokeapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Fixture bundles mirror PokeAPI's URL paths: the response for
+// /api/v2/pokemon/pikachu lives at <dir>/api/v2/pokemon/pikachu/index.json.
+// Requests with a query string, such as listing pages, are stored next to
+// it as index@limit=20&offset=20.json.
+func fixturePath(dir string, req *http.Request) string {
+	name := "index.json"
+	if query := req.URL.Query(); len(query) > 0 {
+		name = "index@" + query.Encode() + ".json"
+	}
+	return filepath.Join(dir, filepath.FromSlash(req.URL.Path), name)
+}
+
+// fixtureTransport answers every request from a fixture bundle and never
+// touches the network. Missing fixtures are reported as 404s.
+type fixtureTransport struct {
+	dir string
+}
+
+func (t fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := os.ReadFile(fixturePath(t.dir, req))
+	if os.IsNotExist(err) {
+		return fixtureResponse(req, http.StatusNotFound, []byte("Not Found")), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	return fixtureResponse(req, http.StatusOK, body), nil
+}
+
+func fixtureResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// recordingTransport passes requests through to next and saves every
+// successful response into a fixture bundle.
+type recordingTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	path := fixturePath(t.dir, req)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	return resp, nil
+}
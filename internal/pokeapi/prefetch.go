@@ -0,0 +1,111 @@
+package pokeapi
+
+import (
+	"context"
+	"sync"
+)
+
+// prefetchQueueSize bounds how many URLs can wait for a worker. Requests
+// beyond it are dropped, since prefetching is only an optimisation.
+const prefetchQueueSize = 64
+
+// prefetcher warms the cache in the background with a fixed pool of
+// workers that stop when their context is cancelled.
+type prefetcher struct {
+	ctx     context.Context
+	jobs    chan string
+	mu      sync.Mutex
+	pending map[string]bool
+	wg      sync.WaitGroup
+}
+
+// StartPrefetch starts workers that fetch URLs passed to Prefetch into the
+// cache until ctx is cancelled. Without a cache there is nothing to warm.
+func (c *Client) StartPrefetch(ctx context.Context, workers int) {
+	if c.cache == nil || c.prefetch != nil {
+		return
+	}
+	p := &prefetcher{
+		ctx:     ctx,
+		jobs:    make(chan string, prefetchQueueSize),
+		pending: make(map[string]bool),
+	}
+	c.prefetch = p
+	for i := 0; i < workers; i++ {
+		go c.prefetchWorker(p)
+	}
+}
+
+func (c *Client) prefetchWorker(p *prefetcher) {
+	for {
+		select {
+		case <-p.ctx.Done():
+			p.drain()
+			return
+		case url := <-p.jobs:
+			if p.ctx.Err() == nil {
+				c.fetch(p.ctx, url)
+			}
+			p.mu.Lock()
+			delete(p.pending, url)
+			p.mu.Unlock()
+			p.wg.Done()
+		}
+	}
+}
+
+// drain discards the URLs still queued once the context is cancelled so
+// WaitPrefetch does not wait on work that will never run. Prefetch checks
+// the context under the same lock, so nothing is queued after the drain.
+func (p *prefetcher) drain() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		select {
+		case url := <-p.jobs:
+			delete(p.pending, url)
+			p.wg.Done()
+		default:
+			return
+		}
+	}
+}
+
+// Prefetch queues urls to be fetched in the background. It never blocks;
+// URLs already cached or queued are skipped, and it does nothing unless
+// StartPrefetch was called.
+func (c *Client) Prefetch(urls ...string) {
+	p := c.prefetch
+	if p == nil {
+		return
+	}
+	for _, url := range urls {
+		if _, found := c.cache.Get(url); found {
+			continue
+		}
+		p.mu.Lock()
+		if p.ctx.Err() != nil {
+			p.mu.Unlock()
+			return
+		}
+		if p.pending[url] {
+			p.mu.Unlock()
+			continue
+		}
+		p.wg.Add(1)
+		select {
+		case p.jobs <- url:
+			p.pending[url] = true
+		default:
+			p.wg.Done()
+		}
+		p.mu.Unlock()
+	}
+}
+
+// WaitPrefetch blocks until every queued URL has been fetched.
+func (c *Client) WaitPrefetch() {
+	if c.prefetch != nil {
+		c.prefetch.wg.Wait()
+	}
+}
@@ -0,0 +1,29 @@
+package pokeapi
+
+import "strings"
+
+// DefaultLanguage is the language text is shown in unless asked otherwise.
+const DefaultLanguage = "en"
+
+// Genus returns the species' category in lang, or "" if it has none.
+func (s PokemonSpecies) Genus(lang string) string {
+	for _, genus := range s.Genera {
+		if genus.Language.Name == lang {
+			return genus.Genus
+		}
+	}
+	return ""
+}
+
+// FlavorText returns the Pokedex entry in lang from the newest game that
+// has one, or "" if there is none. The games wrap entries with newlines
+// and form feeds, so whitespace is collapsed into single spaces.
+func (s PokemonSpecies) FlavorText(lang string) string {
+	for i := len(s.FlavorTextEntries) - 1; i >= 0; i-- {
+		entry := s.FlavorTextEntries[i]
+		if entry.Language.Name == lang {
+			return strings.Join(strings.Fields(entry.FlavorText), " ")
+		}
+	}
+	return ""
+}
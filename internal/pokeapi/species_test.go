@@ -0,0 +1,37 @@
+package pokeapi
+
+import "testing"
+
+func TestSpeciesText(t *testing.T) {
+	species := PokemonSpecies{
+		Genera: []Genus{
+			{Genus: "Mouse Pokémon", Language: NamedAPIResource{Name: "en"}},
+			{Genus: "Maus-Pokémon", Language: NamedAPIResource{Name: "de"}},
+		},
+		FlavorTextEntries: []FlavorText{
+			{FlavorText: "When several of\nthese POKéMON\ngather,", Language: NamedAPIResource{Name: "en"}},
+			{FlavorText: "Es lebt in Wäldern.", Language: NamedAPIResource{Name: "de"}},
+			{FlavorText: "It stores\felectricity  in its cheeks.", Language: NamedAPIResource{Name: "en"}},
+		},
+	}
+	cases := []struct {
+		name          string
+		lang          string
+		expectedGenus string
+		expectedText  string
+	}{
+		{name: "english uses the newest entry", lang: "en", expectedGenus: "Mouse Pokémon", expectedText: "It stores electricity in its cheeks."},
+		{name: "german", lang: "de", expectedGenus: "Maus-Pokémon", expectedText: "Es lebt in Wäldern."},
+		{name: "missing language", lang: "ja", expectedGenus: "", expectedText: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := species.Genus(c.lang); actual != c.expectedGenus {
+				t.Errorf("Expected: %v, Got: %v", c.expectedGenus, actual)
+			}
+			if actual := species.FlavorText(c.lang); actual != c.expectedText {
+				t.Errorf("Expected: %v, Got: %v", c.expectedText, actual)
+			}
+		})
+	}
+}
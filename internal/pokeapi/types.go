@@ -1,11 +1,5 @@
 package pokeapi
 
-type LocationAreaResponse struct {
-	Count    int            "json: count"
-	Next     *string        "json: next"
-	Previous *string        "json: previous"
-	Results  []LocationArea "json: results"
-}
 type EncounterResponse struct {
 	EncounterMethodRates []struct {
 		EncounterMethod struct {
@@ -39,31 +33,137 @@ type EncounterResponse struct {
 			Name string `json:"name"`
 			URL  string `json:"url"`
 		} `json:"pokemon"`
-		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance"`
-				ConditionValues []any `json:"condition_values"`
-				MaxLevel        int   `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version"`
-		} `json:"version_details"`
+		VersionDetails []VersionEncounterDetail `json:"version_details"`
 	} `json:"pokemon_encounters"`
 }
 
-type LocationArea struct {
+// NamedAPIResourceList is the shape of every PokeAPI listing endpoint.
+type NamedAPIResourceList struct {
+	Count    int                `json:"count"`
+	Next     *string            `json:"next"`
+	Previous *string            `json:"previous"`
+	Results  []NamedAPIResource `json:"results"`
+}
+
+type NamedAPIResource struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
 }
 
+// LocationAreaEncounter is one area a Pokemon can be met in, from the
+// pokemon/{name}/encounters endpoint.
+type LocationAreaEncounter struct {
+	LocationArea   NamedAPIResource         `json:"location_area"`
+	VersionDetails []VersionEncounterDetail `json:"version_details"`
+}
+
+type VersionEncounterDetail struct {
+	Version          NamedAPIResource `json:"version"`
+	MaxChance        int              `json:"max_chance"`
+	EncounterDetails []Encounter      `json:"encounter_details"`
+}
+
+type Encounter struct {
+	MinLevel        int                `json:"min_level"`
+	MaxLevel        int                `json:"max_level"`
+	ConditionValues []NamedAPIResource `json:"condition_values"`
+	Chance          int                `json:"chance"`
+	Method          NamedAPIResource   `json:"method"`
+}
+
+// PokemonSpecies is what all forms of a Pokemon have in common.
+type PokemonSpecies struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	CaptureRate   int    `json:"capture_rate"`
+	BaseHappiness int    `json:"base_happiness"`
+	// GenderRate is the chance of being female in eighths, or -1 for
+	// species without a gender.
+	GenderRate        int              `json:"gender_rate"`
+	IsBaby            bool             `json:"is_baby"`
+	IsLegendary       bool             `json:"is_legendary"`
+	IsMythical        bool             `json:"is_mythical"`
+	GrowthRate        NamedAPIResource `json:"growth_rate"`
+	EvolutionChain    APIResource      `json:"evolution_chain"`
+	Genera            []Genus          `json:"genera"`
+	FlavorTextEntries []FlavorText     `json:"flavor_text_entries"`
+}
+
+// Genus is a species' category, such as "Mouse Pokemon", in one language.
+type Genus struct {
+	Genus    string           `json:"genus"`
+	Language NamedAPIResource `json:"language"`
+}
+
+// FlavorText is a Pokedex entry from one game version in one language.
+type FlavorText struct {
+	FlavorText string           `json:"flavor_text"`
+	Language   NamedAPIResource `json:"language"`
+	Version    NamedAPIResource `json:"version"`
+}
+
+// APIResource refers to a resource that has no name, only a URL.
+type APIResource struct {
+	URL string `json:"url"`
+}
+
+// EvolutionChain is the family tree of a species, starting from its
+// earliest stage.
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+// ChainLink is one species in an evolution chain. EvolutionDetails says
+// how the previous stage evolves into it and is empty for the first stage.
+type ChainLink struct {
+	IsBaby           bool              `json:"is_baby"`
+	Species          NamedAPIResource  `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+// EvolutionDetail is one way to evolve. Trigger says what starts the
+// evolution; every other field that is set is a condition that must hold.
+type EvolutionDetail struct {
+	Trigger               NamedAPIResource  `json:"trigger"`
+	Item                  *NamedAPIResource `json:"item"`
+	HeldItem              *NamedAPIResource `json:"held_item"`
+	KnownMove             *NamedAPIResource `json:"known_move"`
+	KnownMoveType         *NamedAPIResource `json:"known_move_type"`
+	Location              *NamedAPIResource `json:"location"`
+	PartySpecies          *NamedAPIResource `json:"party_species"`
+	PartyType             *NamedAPIResource `json:"party_type"`
+	TradeSpecies          *NamedAPIResource `json:"trade_species"`
+	Gender                *int              `json:"gender"`
+	MinLevel              *int              `json:"min_level"`
+	MinHappiness          *int              `json:"min_happiness"`
+	MinBeauty             *int              `json:"min_beauty"`
+	MinAffection          *int              `json:"min_affection"`
+	RelativePhysicalStats *int              `json:"relative_physical_stats"`
+	TimeOfDay             string            `json:"time_of_day"`
+	NeedsOverworldRain    bool              `json:"needs_overworld_rain"`
+	TurnUpsideDown        bool              `json:"turn_upside_down"`
+}
+
+// Region is the top of PokeAPI's map hierarchy, such as kanto. It is made
+// up of locations.
+type Region struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	MainGeneration NamedAPIResource   `json:"main_generation"`
+	Locations      []NamedAPIResource `json:"locations"`
+}
+
+// Location is a place within a region, such as a town or route. It is
+// made up of the location areas that can be explored.
+type Location struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Region NamedAPIResource   `json:"region"`
+	Areas  []NamedAPIResource `json:"areas"`
+}
+
 type Pokemon struct {
 	ID             int    `json:"id"`
 	Name           string `json:"name"`
@@ -2,35 +2,65 @@ package pokecache
 
 import "time"
 
-func NewCache(interval time.Duration) Cache {
-	cache := Cache{}
+func NewCache(interval time.Duration, opts ...Option) *Cache {
+	cache := &Cache{}
 	cache.entries = make(map[string]cacheEntry)
 	cache.interval = interval
+	for _, opt := range opts {
+		opt(cache)
+	}
 	go cache.reapLoop()
 	return cache
 }
 
-func (c Cache) Add(key string, val []byte) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.entries[key] = cacheEntry{
+// WithDisk adds a persistent tier stored in dir. Entries on disk expire
+// after ttl, or after the cache interval when ttl is zero.
+func WithDisk(dir string, ttl time.Duration) Option {
+	return func(c *Cache) {
+		if ttl == 0 {
+			ttl = c.interval
+		}
+		c.disk = &diskStore{dir: dir, ttl: ttl}
+	}
+}
+
+func (c *Cache) Add(key string, val []byte) {
+	entry := cacheEntry{
 		createdAt: time.Now(),
 		val:       val,
 	}
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	if c.disk != nil {
+		c.disk.write(key, entry)
+	}
 }
 
-func (c Cache) Get(key string) ([]byte, bool) {
+func (c *Cache) Get(key string) ([]byte, bool) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	value, exists := c.entries[key]
+	c.mu.Unlock()
 	if exists {
 		return value.val, exists
 	}
-	return nil, exists
-
+	if c.disk == nil {
+		return nil, false
+	}
+	value, exists = c.disk.read(key)
+	if !exists {
+		return nil, false
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{
+		createdAt: time.Now(),
+		val:       value.val,
+	}
+	c.mu.Unlock()
+	return value.val, true
 }
 
-func (c Cache) reapLoop() {
+func (c *Cache) reapLoop() {
 	ticker := time.NewTicker(c.interval)
 	for range ticker.C {
 		c.mu.Lock()
@@ -41,6 +71,9 @@ func (c Cache) reapLoop() {
 			}
 		}
 		c.mu.Unlock()
+		if c.disk != nil {
+			c.disk.reap()
+		}
 	}
 
 }
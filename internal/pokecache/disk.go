@@ -0,0 +1,94 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The disk tier is best effort: a failed read is treated as a miss and a
+// failed write leaves the entry in memory only.
+
+func (d *diskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *diskStore) write(key string, entry cacheEntry) {
+	body, err := json.Marshal(diskEntry{
+		Key:       key,
+		CreatedAt: entry.createdAt,
+		Val:       entry.val,
+	})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(body)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+	os.Rename(tmp.Name(), d.path(key))
+}
+
+func (d *diskStore) read(key string) (cacheEntry, bool) {
+	path := d.path(key)
+	entry, ok := d.load(path)
+	if !ok || entry.Key != key {
+		return cacheEntry{}, false
+	}
+	if d.expired(entry) {
+		os.Remove(path)
+		return cacheEntry{}, false
+	}
+	return cacheEntry{createdAt: entry.CreatedAt, val: entry.Val}, true
+}
+
+func (d *diskStore) load(path string) (diskEntry, bool) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return diskEntry{}, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return diskEntry{}, false
+	}
+	return entry, true
+}
+
+func (d *diskStore) expired(entry diskEntry) bool {
+	return time.Since(entry.CreatedAt) > d.ttl
+}
+
+// reap removes entries whose file is older than the ttl. Files are written
+// once when their entry is created, so the modification time stands in
+// for CreatedAt without decoding every file.
+func (d *diskStore) reap() {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > d.ttl {
+			os.Remove(filepath.Join(d.dir, file.Name()))
+		}
+	}
+}
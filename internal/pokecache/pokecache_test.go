@@ -2,6 +2,7 @@ package pokecache
 
 import (
 	"fmt"
+	"os"
 	"testing"
 	"time"
 )
@@ -59,3 +60,70 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestDiskPersistsAcrossCaches(t *testing.T) {
+	const interval = 5 * time.Second
+	dir := t.TempDir()
+	first := NewCache(interval, WithDisk(dir, time.Hour))
+	first.Add("https://example.com", []byte("testdata"))
+
+	second := NewCache(interval, WithDisk(dir, time.Hour))
+	val, ok := second.Get("https://example.com")
+	if !ok {
+		t.Errorf("expected to find key on disk")
+		return
+	}
+	if string(val) != "testdata" {
+		t.Errorf("expected to find value")
+		return
+	}
+
+	_, ok = second.Get("https://example.com/missing")
+	if ok {
+		t.Errorf("expected to not find key")
+	}
+}
+
+func TestDiskReapLoop(t *testing.T) {
+	const baseTime = 5 * time.Millisecond
+	const waitTime = baseTime + 5*time.Millisecond
+	dir := t.TempDir()
+	cache := NewCache(baseTime, WithDisk(dir, 0))
+	cache.Add("https://example.com", []byte("testdata"))
+
+	time.Sleep(waitTime)
+
+	_, ok := cache.Get("https://example.com")
+	if ok {
+		t.Errorf("expected to not find key")
+		return
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected expired entries to be removed from disk, found %v", len(files))
+	}
+}
+
+func TestDiskReapUsesModTime(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(time.Hour, WithDisk(dir, time.Minute))
+	cache.Add("https://example.com/stale", []byte("stale"))
+	cache.Add("https://example.com/fresh", []byte("fresh"))
+
+	stale := cache.disk.path("https://example.com/stale")
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+	cache.disk.reap()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected the stale entry to be removed, got %v", err)
+	}
+	if _, err := os.Stat(cache.disk.path("https://example.com/fresh")); err != nil {
+		t.Errorf("expected the fresh entry to be kept, got %v", err)
+	}
+}
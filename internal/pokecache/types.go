@@ -6,12 +6,30 @@ import (
 )
 
 type Cache struct {
-	entries  map[string]cacheEntry
+	entries map[string]cacheEntry
+	// mu guards entries only. The disk tier is read and written outside
+	// of it so lookups never wait on the filesystem.
 	mu       sync.Mutex
 	interval time.Duration
+	disk     *diskStore
 }
 
 type cacheEntry struct {
 	createdAt time.Time
 	val       []byte
 }
+
+// Option configures optional behaviour of a Cache created by NewCache.
+type Option func(*Cache)
+
+// diskStore keeps one file per entry in dir, named by the SHA-256 of its key.
+type diskStore struct {
+	dir string
+	ttl time.Duration
+}
+
+type diskEntry struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+	Val       []byte    `json:"val"`
+}
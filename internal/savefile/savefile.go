@@ -0,0 +1,213 @@
+package savefile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/trainer"
+)
+
+var (
+	ErrCorrupt            = errors.New("save file is corrupt")
+	ErrUnsupportedVersion = errors.New("save file version is not supported")
+)
+
+// migrations maps a version to the step that upgrades it to version+1.
+var migrations = map[int]migration{
+	1: addLocation,
+	2: addBag,
+	3: addEntryLevels,
+	4: moveToPC,
+	5: dropCatchAttempts,
+}
+
+// addLocation starts version 1 saves outside of any area.
+func addLocation(raw map[string]any) error {
+	raw["location"] = ""
+	return nil
+}
+
+// addBag hands version 2 saves the bag of a new trainer.
+func addBag(raw map[string]any) error {
+	raw["bag"] = trainer.NewBag()
+	return nil
+}
+
+// migratedLevel is the level given to Pokemon caught before levels were
+// tracked, the level a starter Pokemon begins at.
+const migratedLevel = 5
+
+// addEntryLevels wraps each version 3 pokedex Pokemon in an entry with a
+// level and an empty history.
+func addEntryLevels(raw map[string]any) error {
+	pokedex, ok := raw["pokedex"].(map[string]any)
+	if !ok {
+		raw["pokedex"] = map[string]any{}
+		return nil
+	}
+	for name, pokemon := range pokedex {
+		pokedex[name] = map[string]any{"pokemon": pokemon, "level": migratedLevel, "history": []any{}}
+	}
+	return nil
+}
+
+// moveToPC turns the version 4 pokedex, which held one Pokemon per species,
+// into a PC of individual Pokemon. IDs follow the species names in order.
+// Traits that were never tracked are marked unknown, IVs and EVs are left
+// empty and the nature is a neutral one.
+func moveToPC(raw map[string]any) error {
+	pokedex, _ := raw["pokedex"].(map[string]any)
+	names := []string{}
+	for name := range pokedex {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pokemon := []any{}
+	for i, name := range names {
+		entry, ok := pokedex[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("pokedex entry %s is not an object", name)
+		}
+		entry["id"] = i + 1
+		entry["ivs"] = map[string]any{}
+		entry["evs"] = map[string]any{}
+		entry["nature"] = trainer.Natures[0].Name
+		entry["xp"] = trainer.UnknownXP
+		entry["gender"] = trainer.Unknown
+		entry["caught_in"] = trainer.Unknown
+		if history, ok := entry["history"].([]any); ok && len(history) > 0 {
+			if first, ok := history[0].(map[string]any); ok {
+				entry["caught_at"] = first["at"]
+			}
+		}
+		pokemon = append(pokemon, entry)
+	}
+	delete(raw, "pokedex")
+	raw["pc"] = map[string]any{"pokemon": pokemon, "next_id": len(names) + 1}
+	return nil
+}
+
+// dropCatchAttempts forgets the per-species count of failed catches that
+// version 5 kept. Failed throws are now counted per wild Pokemon.
+func dropCatchAttempts(raw map[string]any) error {
+	delete(raw, "catch_attempts")
+	return nil
+}
+
+func New() Data {
+	return Data{
+		Version: CurrentVersion,
+		PC:      trainer.PC{Pokemon: []trainer.Entry{}, NextID: 1},
+		Bag:     trainer.NewBag(),
+	}
+}
+
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "pokedexcli", "save.json"), nil
+}
+
+// Load reads the save file at path. A missing file is not an error and
+// returns an empty save. Files from older versions are migrated in memory.
+func Load(path string) (Data, error) {
+	body, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to read save file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return Data{}, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	version, ok := raw["version"].(float64)
+	if !ok {
+		return Data{}, fmt.Errorf("%w: missing version", ErrCorrupt)
+	}
+	if err := migrate(raw, int(version)); err != nil {
+		return Data{}, err
+	}
+
+	body, err = json.Marshal(raw)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to marshal migrated save: %w", err)
+	}
+	data := New()
+	if err := json.Unmarshal(body, &data); err != nil {
+		return Data{}, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if data.PC.Pokemon == nil {
+		data.PC.Pokemon = []trainer.Entry{}
+	}
+	if data.Bag == nil {
+		data.Bag = trainer.NewBag()
+	}
+	return data, nil
+}
+
+func migrate(raw map[string]any, version int) error {
+	if version > CurrentVersion {
+		return fmt.Errorf("%w: version %d is newer than %d", ErrUnsupportedVersion, version, CurrentVersion)
+	}
+	for ; version < CurrentVersion; version++ {
+		step, ok := migrations[version]
+		if !ok {
+			return fmt.Errorf("%w: no migration from version %d", ErrCorrupt, version)
+		}
+		if err := step(raw); err != nil {
+			return fmt.Errorf("failed to migrate save from version %d: %w", version, err)
+		}
+		raw["version"] = float64(version + 1)
+	}
+	return nil
+}
+
+// Save writes data to path, replacing the previous file atomically.
+func Save(path string, data Data) error {
+	data.Version = CurrentVersion
+	body, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal save: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create save directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create save file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace save file: %w", err)
+	}
+	return nil
+}
+
+// Quarantine moves an unreadable save file aside so a fresh one can be
+// started without losing the original. It returns the backup location.
+func Quarantine(path string) (string, error) {
+	backup := fmt.Sprintf("%s.bak-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("failed to move save file aside: %w", err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,143 @@
+package savefile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+	"github.com/thmastin/pokedexcli/internal/trainer"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "save.json")
+	data := New()
+	data.PC.Add(trainer.Entry{Pokemon: pokeapi.Pokemon{Name: "pikachu", BaseExperience: 112}, Level: 12, Nickname: "sparky"})
+	data.PC.Add(trainer.Entry{Pokemon: pokeapi.Pokemon{Name: "pikachu"}, Level: 7})
+	data.Location = "viridian-forest-area"
+	data.Bag["master-ball"] = 0
+
+	if err := Save(path, data); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	if loaded.Version != CurrentVersion {
+		t.Errorf("Expected version: %v, Got: %v", CurrentVersion, loaded.Version)
+	}
+	if len(loaded.PC.Pokemon) != 2 || loaded.PC.NextID != 3 {
+		t.Fatalf("expected both pikachu to be saved, got %+v", loaded.PC)
+	}
+	first := loaded.PC.Pokemon[0]
+	if first.ID != 1 || first.Nickname != "sparky" || first.Pokemon.BaseExperience != 112 || first.Level != 12 {
+		t.Errorf("expected the first pikachu to be saved, got %+v", first)
+	}
+	if loaded.Bag["master-ball"] != 0 || loaded.Bag["poke-ball"] != 20 {
+		t.Errorf("expected the bag to be saved, got %v", loaded.Bag)
+	}
+	if loaded.Location != "viridian-forest-area" {
+		t.Errorf("Expected location: viridian-forest-area, Got: %v", loaded.Location)
+	}
+}
+
+func TestLoadMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	contents := `{"version": 1, "pokedex": {"pikachu": {"name": "pikachu"}, "eevee": {"name": "eevee"}}, "catch_attempts": {"staryu": 1}}`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Version != CurrentVersion {
+		t.Errorf("Expected version: %v, Got: %v", CurrentVersion, data.Version)
+	}
+	if len(data.PC.Pokemon) != 2 {
+		t.Errorf("expected the version 1 data to survive, got %+v", data)
+	}
+	if data.Location != "" {
+		t.Errorf("Expected no location, Got: %v", data.Location)
+	}
+	if data.Bag["poke-ball"] != trainer.NewBag()["poke-ball"] {
+		t.Errorf("expected a new trainer's bag, got %v", data.Bag)
+	}
+	pikachu := data.PC.Pokemon[1]
+	if pikachu.ID != 2 || pikachu.Pokemon.Name != "pikachu" || pikachu.Level != migratedLevel || pikachu.Nature != "hardy" || pikachu.Gender != trainer.Unknown || pikachu.CaughtIn != trainer.Unknown || pikachu.XP != trainer.UnknownXP {
+		t.Errorf("expected pikachu to become #2 at level %v, got %+v", migratedLevel, pikachu)
+	}
+	if data.PC.NextID != 3 {
+		t.Errorf("Expected next id: 3, Got: %v", data.PC.NextID)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	data, err := Load(filepath.Join(t.TempDir(), "save.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.PC.Pokemon == nil || len(data.PC.Pokemon) != 0 || data.PC.NextID != 1 {
+		t.Errorf("expected an empty pc, got %+v", data.PC)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		expected error
+	}{
+		{
+			name:     "not json",
+			contents: "{not json",
+			expected: ErrCorrupt,
+		},
+		{
+			name:     "missing version",
+			contents: `{"pokedex": {}}`,
+			expected: ErrCorrupt,
+		},
+		{
+			name:     "newer version",
+			contents: `{"version": 999}`,
+			expected: ErrUnsupportedVersion,
+		},
+		{
+			name:     "version without migration",
+			contents: `{"version": -1}`,
+			expected: ErrCorrupt,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := os.WriteFile(path, []byte(c.contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if !errors.Is(err, c.expected) {
+				t.Errorf("Expected: %v, Got: %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := Quarantine(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected original save to be moved")
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("expected backup at %s: %v", backup, err)
+	}
+}
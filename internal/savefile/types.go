@@ -0,0 +1,18 @@
+package savefile
+
+import "github.com/thmastin/pokedexcli/internal/trainer"
+
+// CurrentVersion is the save file format written by Save. Bump it whenever
+// Data changes shape and register a migration from the previous version.
+const CurrentVersion = 6
+
+type Data struct {
+	Version int        `json:"version"`
+	PC      trainer.PC `json:"pc"`
+	// Location is the location area the trainer was last in.
+	Location string      `json:"location"`
+	Bag      trainer.Bag `json:"bag"`
+}
+
+// migration upgrades the raw JSON of a save file by exactly one version.
+type migration func(raw map[string]any) error
@@ -0,0 +1,73 @@
+package trainer
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Balls lists every ball the bag can hold, weakest first.
+var Balls = []Ball{
+	{Name: "poke-ball", Display: "Poke Ball", Bonus: 1},
+	{Name: "great-ball", Display: "Great Ball", Bonus: 1.5},
+	{Name: "ultra-ball", Display: "Ultra Ball", Bonus: 2},
+	{Name: "master-ball", Display: "Master Ball", Guaranteed: true},
+}
+
+// NewBag is the bag a new trainer starts with.
+func NewBag() Bag {
+	return Bag{
+		"poke-ball":   20,
+		"great-ball":  5,
+		"ultra-ball":  2,
+		"master-ball": 1,
+	}
+}
+
+// findOdds is one in how many walks turn up a ball lying on the ground.
+const findOdds = 5
+
+// FindBall rolls whether a walk turns up a ball, usually a Poke Ball and
+// now and then a Great Ball, and adds it to the bag.
+func (b Bag) FindBall(rng *rand.Rand) (Ball, bool) {
+	if rng.Intn(findOdds) != 0 {
+		return Ball{}, false
+	}
+	ball := Balls[0]
+	if rng.Intn(4) == 0 {
+		ball = Balls[1]
+	}
+	b[ball.Name]++
+	return ball, true
+}
+
+// ParseBall accepts a ball's item name or its first word, such as "great".
+func ParseBall(name string) (Ball, error) {
+	name = strings.TrimSuffix(name, "-ball")
+	for _, ball := range Balls {
+		if strings.TrimSuffix(ball.Name, "-ball") == name {
+			return ball, nil
+		}
+	}
+	return Ball{}, fmt.Errorf("unknown ball: %s, use poke, great, ultra or master", name)
+}
+
+// Use takes one ball out of the bag.
+func (b Bag) Use(ball Ball) error {
+	if b[ball.Name] <= 0 {
+		return fmt.Errorf("%w %ss", ErrOutOfBalls, ball.Display)
+	}
+	b[ball.Name]--
+	return nil
+}
+
+// CatchChance is the probability that ball catches a wild Pokemon at full
+// health. It follows the third generation formula, where the modified rate
+// captureRate*bonus/3 out of 255 approximates the chance of all four shake
+// checks passing.
+func CatchChance(captureRate int, ball Ball) float64 {
+	if ball.Guaranteed {
+		return 1
+	}
+	return min(1, float64(captureRate)*ball.Bonus/3/255)
+}
@@ -0,0 +1,55 @@
+package trainer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+const (
+	// maxLevel is the highest level a Pokemon can reach.
+	maxLevel = 100
+	// maxEV and maxTotalEVs cap the effort values of one stat and of all
+	// stats together.
+	maxEV       = 252
+	maxTotalEVs = 510
+)
+
+// Defeat credits the entry with beating a wild Pokemon at level. It gains
+// the main series experience for a wild win and the defeated Pokemon's
+// effort values, and grows a level each time its experience reaches the
+// next level of growthRate. It returns the experience and levels gained.
+func (e *Entry) Defeat(defeated pokeapi.Pokemon, level int, growthRate string) (xp, levels int) {
+	xp = max(1, defeated.BaseExperience*level/7)
+	// Entries from old saves have no experience yet; they start from
+	// what their level needs.
+	e.XP = max(e.XP, Experience(growthRate, e.Level)) + xp
+
+	if e.EVs == nil {
+		e.EVs = map[string]int{}
+	}
+	total := 0
+	for _, ev := range e.EVs {
+		total += ev
+	}
+	for _, stat := range defeated.Stats {
+		gain := min(stat.Effort, maxEV-e.EVs[stat.Stat.Name], maxTotalEVs-total)
+		if gain > 0 {
+			e.EVs[stat.Stat.Name] += gain
+			total += gain
+		}
+	}
+
+	for e.Level < maxLevel && e.XP >= Experience(growthRate, e.Level+1) {
+		e.Level++
+		levels++
+	}
+	if levels > 0 {
+		e.History = append(e.History, Event{
+			At:          time.Now(),
+			Description: fmt.Sprintf("grew to level %d by defeating a wild %s", e.Level, defeated.Name),
+		})
+	}
+	return xp, levels
+}
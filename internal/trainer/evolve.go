@@ -0,0 +1,156 @@
+package trainer
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+// FindLink returns the stage of species in an evolution chain.
+func FindLink(link pokeapi.ChainLink, species string) (pokeapi.ChainLink, bool) {
+	if link.Species.Name == species {
+		return link, true
+	}
+	for _, next := range link.EvolvesTo {
+		if found, ok := FindLink(next, species); ok {
+			return found, true
+		}
+	}
+	return pokeapi.ChainLink{}, false
+}
+
+// CanEvolve checks whether entry meets any of the ways into the next stage.
+// Level, items, trading, gender, time of day and attack against defense
+// are checked. Conditions the game does not model, such as happiness or
+// known moves, are never met.
+func CanEvolve(entry Entry, next pokeapi.ChainLink, evolution Evolution) error {
+	reasons := []string{}
+	possible := false
+	for _, detail := range next.EvolutionDetails {
+		reason, supported := unmet(entry, detail, evolution)
+		if reason == "" {
+			return nil
+		}
+		if !supported {
+			reason += " (not supported)"
+		}
+		possible = possible || supported
+		reasons = append(reasons, reason)
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("%s %w into %s", entry.Name(), ErrCannotEvolve, next.Species.Name)
+	}
+	yet := ""
+	if possible {
+		yet = " yet"
+	}
+	return fmt.Errorf("%s %w into %s%s, it needs %s", entry.Name(), ErrCannotEvolve, next.Species.Name, yet, strings.Join(reasons, " or "))
+}
+
+// unmet describes the first condition of detail that entry does not meet,
+// or returns "" if it meets them all. supported is false when the
+// condition is one the game cannot check.
+func unmet(entry Entry, detail pokeapi.EvolutionDetail, evolution Evolution) (reason string, supported bool) {
+	if reason := unsupported(detail); reason != "" {
+		return reason, false
+	}
+	switch detail.Trigger.Name {
+	case "use-item":
+		if detail.Item != nil && evolution.Item != detail.Item.Name {
+			return "--item " + detail.Item.Name, true
+		}
+	case "trade":
+		if !evolution.Trade {
+			return "--trade", true
+		}
+	}
+	if detail.HeldItem != nil && evolution.Item != detail.HeldItem.Name {
+		return "--item " + detail.HeldItem.Name, true
+	}
+	if detail.MinLevel != nil && entry.Level < *detail.MinLevel {
+		return fmt.Sprintf("level %d", *detail.MinLevel), true
+	}
+	if detail.Gender != nil {
+		gender := map[int]string{1: "female", 2: "male"}[*detail.Gender]
+		if entry.Gender != gender {
+			return "to be " + gender, true
+		}
+	}
+	if detail.TimeOfDay != "" && timeOfDay(evolution.Time) != detail.TimeOfDay {
+		return detail.TimeOfDay + "time", true
+	}
+	if detail.RelativePhysicalStats != nil {
+		if physical := relativePhysicalStats(entry); physical != *detail.RelativePhysicalStats {
+			return []string{"attack below defense", "attack equal to defense", "attack above defense"}[*detail.RelativePhysicalStats+1], true
+		}
+	}
+	return "", true
+}
+
+// unsupported describes a condition of detail that the game does not
+// track, or returns "" if there is none.
+func unsupported(detail pokeapi.EvolutionDetail) string {
+	switch detail.Trigger.Name {
+	case "level-up", "use-item", "trade":
+	default:
+		return strings.ReplaceAll(detail.Trigger.Name, "-", " ")
+	}
+	switch {
+	case detail.MinHappiness != nil:
+		return fmt.Sprintf("happiness %d", *detail.MinHappiness)
+	case detail.MinAffection != nil:
+		return fmt.Sprintf("affection %d", *detail.MinAffection)
+	case detail.MinBeauty != nil:
+		return fmt.Sprintf("beauty %d", *detail.MinBeauty)
+	case detail.KnownMove != nil:
+		return "knowing " + detail.KnownMove.Name
+	case detail.KnownMoveType != nil:
+		return "knowing a " + detail.KnownMoveType.Name + " move"
+	case detail.Location != nil:
+		return "being at " + detail.Location.Name
+	case detail.PartySpecies != nil:
+		return detail.PartySpecies.Name + " in the party"
+	case detail.PartyType != nil:
+		return "a " + detail.PartyType.Name + " Pokemon in the party"
+	case detail.TradeSpecies != nil:
+		return "a trade for " + detail.TradeSpecies.Name
+	case detail.NeedsOverworldRain:
+		return "rain"
+	case detail.TurnUpsideDown:
+		return "turning the console upside down"
+	case detail.TimeOfDay != "" && detail.TimeOfDay != "day" && detail.TimeOfDay != "night":
+		return detail.TimeOfDay
+	}
+	return ""
+}
+
+// timeOfDay is "day" from 6:00 until 18:00 and "night" otherwise.
+func timeOfDay(t time.Time) string {
+	if hour := t.Hour(); hour >= 6 && hour < 18 {
+		return "day"
+	}
+	return "night"
+}
+
+// relativePhysicalStats compares the entry's attack with its defense the
+// way PokeAPI does: 1 if higher, 0 if equal and -1 if lower.
+func relativePhysicalStats(entry Entry) int {
+	values := map[string]int{}
+	for _, stat := range entry.Stats() {
+		values[stat.Name] = stat.Value
+	}
+	return cmp.Compare(values["attack"], values["defense"])
+}
+
+// Evolve turns entry into evolved, keeping its level and recording the
+// evolution in its history.
+func (e *Entry) Evolve(evolved pokeapi.Pokemon) {
+	e.History = append(e.History, Event{
+		At:          time.Now(),
+		Description: fmt.Sprintf("evolved from %s into %s at level %d", e.Pokemon.Name, evolved.Name, e.Level),
+	})
+	e.Pokemon = evolved
+}
@@ -0,0 +1,265 @@
+package trainer
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+// Natures are the 25 natures in their main series order.
+var Natures = []Nature{
+	{"hardy", "attack", "attack"},
+	{"lonely", "attack", "defense"},
+	{"brave", "attack", "speed"},
+	{"adamant", "attack", "special-attack"},
+	{"naughty", "attack", "special-defense"},
+	{"bold", "defense", "attack"},
+	{"docile", "defense", "defense"},
+	{"relaxed", "defense", "speed"},
+	{"impish", "defense", "special-attack"},
+	{"lax", "defense", "special-defense"},
+	{"timid", "speed", "attack"},
+	{"hasty", "speed", "defense"},
+	{"serious", "speed", "speed"},
+	{"jolly", "speed", "special-attack"},
+	{"naive", "speed", "special-defense"},
+	{"modest", "special-attack", "attack"},
+	{"mild", "special-attack", "defense"},
+	{"quiet", "special-attack", "speed"},
+	{"bashful", "special-attack", "special-attack"},
+	{"rash", "special-attack", "special-defense"},
+	{"calm", "special-defense", "attack"},
+	{"gentle", "special-defense", "defense"},
+	{"sassy", "special-defense", "speed"},
+	{"careful", "special-defense", "special-attack"},
+	{"quirky", "special-defense", "special-defense"},
+}
+
+// shinyOdds is one in how many Pokemon are shiny.
+const shinyOdds = 4096
+
+// maxNickname is the longest nickname the games allow.
+const maxNickname = 12
+
+// NewEntry turns a caught wild Pokemon into an entry. It keeps the wild
+// Pokemon's IVs and rolls its nature, gender and whether it is shiny. The
+// entry gets its ID when it is added to a PC.
+func NewEntry(pokemon pokeapi.Pokemon, species pokeapi.PokemonSpecies, wild Wild, location string, rng *rand.Rand) Entry {
+	now := time.Now()
+	entry := Entry{
+		Pokemon:  pokemon,
+		Level:    wild.Level,
+		XP:       Experience(species.GrowthRate.Name, wild.Level),
+		IVs:      map[string]int{},
+		EVs:      map[string]int{},
+		Nature:   Natures[rng.Intn(len(Natures))].Name,
+		Gender:   "genderless",
+		CaughtAt: now,
+		CaughtIn: location,
+		History: []Event{{
+			At:          now,
+			Description: fmt.Sprintf("caught at level %d in %s", wild.Level, location),
+		}},
+	}
+	for _, stat := range wild.Stats {
+		entry.IVs[stat.Name] = stat.IV
+		entry.EVs[stat.Name] = 0
+	}
+	if species.GenderRate >= 0 {
+		entry.Gender = "male"
+		if rng.Intn(8) < species.GenderRate {
+			entry.Gender = "female"
+		}
+	}
+	entry.Shiny = rng.Intn(shinyOdds) == 0
+	return entry
+}
+
+// Experience is the total experience a Pokemon of growthRate needs to
+// reach level. Unknown growth rates are treated as medium.
+func Experience(growthRate string, level int) int {
+	n := level
+	cube := n * n * n
+	switch growthRate {
+	case "fast":
+		return 4 * cube / 5
+	case "slow":
+		return 5 * cube / 4
+	case "medium-slow":
+		return max(0, 6*cube/5-15*n*n+100*n-140)
+	case "slow-then-very-fast":
+		switch {
+		case n < 50:
+			return cube * (100 - n) / 50
+		case n < 68:
+			return cube * (150 - n) / 100
+		case n < 98:
+			return cube * ((1911 - 10*n) / 3) / 500
+		default:
+			return cube * (160 - n) / 100
+		}
+	case "fast-then-very-slow":
+		switch {
+		case n < 15:
+			return cube * ((n+1)/3 + 24) / 50
+		case n < 36:
+			return cube * (n + 14) / 50
+		default:
+			return cube * (n/2 + 32) / 50
+		}
+	default:
+		return cube
+	}
+}
+
+// statValue derives a stat with the main series formula.
+func statValue(name string, base, iv, ev, level int, nature Nature) int {
+	value := (2*base + iv + ev/4) * level / 100
+	if name == "hp" {
+		return value + level + 10
+	}
+	value += 5
+	if nature.Increased == nature.Decreased {
+		return value
+	}
+	switch name {
+	case nature.Increased:
+		return value * 11 / 10
+	case nature.Decreased:
+		return value * 9 / 10
+	}
+	return value
+}
+
+// SpeciesName is the species a Pokemon belongs to. Forms such as
+// "deoxys-attack" share the species of their base form.
+func SpeciesName(pokemon pokeapi.Pokemon) string {
+	if pokemon.Species.Name == "" {
+		return pokemon.Name
+	}
+	return pokemon.Species.Name
+}
+
+// Species is the species the entry belongs to.
+func (e Entry) Species() string {
+	return SpeciesName(e.Pokemon)
+}
+
+// Name is the entry's nickname, or the name of its Pokemon.
+func (e Entry) Name() string {
+	if e.Nickname != "" {
+		return e.Nickname
+	}
+	return e.Pokemon.Name
+}
+
+// Stats derives the entry's stats from its Pokemon's base stats and its
+// own level, IVs, EVs and nature.
+func (e Entry) Stats() []Stat {
+	nature := Nature{}
+	for _, n := range Natures {
+		if n.Name == e.Nature {
+			nature = n
+		}
+	}
+	stats := []Stat{}
+	for _, stat := range e.Pokemon.Stats {
+		iv := e.IVs[stat.Stat.Name]
+		value := statValue(stat.Stat.Name, stat.BaseStat, iv, e.EVs[stat.Stat.Name], e.Level, nature)
+		stats = append(stats, Stat{Name: stat.Stat.Name, IV: iv, Value: value})
+	}
+	return stats
+}
+
+// CheckNickname reports why name cannot be used as a nickname. Names that
+// look like an ID are refused so Find can tell them apart.
+func CheckNickname(name string) error {
+	if len([]rune(name)) > maxNickname {
+		return fmt.Errorf("nicknames can be at most %d characters", maxNickname)
+	}
+	if strings.HasPrefix(name, "#") || strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' }) == -1 {
+		return fmt.Errorf("nicknames cannot be a number or start with #")
+	}
+	return nil
+}
+
+// Add stores entry under the next free ID and returns it.
+func (pc *PC) Add(entry Entry) Entry {
+	pc.NextID = max(pc.NextID, 1)
+	entry.ID = pc.NextID
+	pc.NextID++
+	pc.Pokemon = append(pc.Pokemon, entry)
+	return entry
+}
+
+// Find looks up a caught Pokemon by ID, written as "3" or "#3", or by
+// nickname or Pokemon name. Nicknames are matched ignoring case. A name
+// has to pick out a single Pokemon, so a nickname that is also another
+// Pokemon's name is reported as ambiguous.
+func (pc *PC) Find(ref string) (*Entry, error) {
+	if id, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		for i := range pc.Pokemon {
+			if pc.Pokemon[i].ID == id {
+				return &pc.Pokemon[i], nil
+			}
+		}
+		return nil, fmt.Errorf("#%d %w", id, ErrNotCaught)
+	}
+
+	matches := []int{}
+	for i, entry := range pc.Pokemon {
+		if (entry.Nickname != "" && strings.EqualFold(entry.Nickname, ref)) || entry.Pokemon.Name == ref {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%s %w", ref, ErrNotCaught)
+	case 1:
+		return &pc.Pokemon[matches[0]], nil
+	}
+	ids := []string{}
+	for _, i := range matches {
+		ids = append(ids, fmt.Sprintf("#%d", pc.Pokemon[i].ID))
+	}
+	return nil, fmt.Errorf("%w %s, pick one by id: %s", ErrAmbiguous, ref, strings.Join(ids, ", "))
+}
+
+// Release removes the Pokemon with id from the PC.
+func (pc *PC) Release(id int) (Entry, bool) {
+	for i, entry := range pc.Pokemon {
+		if entry.ID == id {
+			pc.Pokemon = slices.Delete(pc.Pokemon, i, i+1)
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Names are the nicknames and Pokemon names a caught Pokemon can be
+// found by, each listed once.
+func (pc *PC) Names() []string {
+	names := []string{}
+	for _, entry := range pc.Pokemon {
+		for _, name := range []string{entry.Nickname, entry.Pokemon.Name} {
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Species is the set of species with at least one caught Pokemon.
+func (pc *PC) Species() map[string]bool {
+	species := make(map[string]bool)
+	for _, entry := range pc.Pokemon {
+		species[entry.Species()] = true
+	}
+	return species
+}
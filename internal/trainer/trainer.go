@@ -0,0 +1,91 @@
+package trainer
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+// NewArea builds an Area from a location area's encounters. A species'
+// chance is summed over its encounter methods in each version, and the
+// best version wins.
+func NewArea(name string, encounter pokeapi.EncounterResponse) Area {
+	area := Area{Name: name, Slots: []Slot{}}
+	for _, encounterEntry := range encounter.PokemonEncounters {
+		slot := Slot{Species: encounterEntry.Pokemon.Name}
+		for _, versionDetails := range encounterEntry.VersionDetails {
+			chance := 0
+			for _, detail := range versionDetails.EncounterDetails {
+				chance += detail.Chance
+				if slot.MinLevel == 0 || detail.MinLevel < slot.MinLevel {
+					slot.MinLevel = detail.MinLevel
+				}
+				slot.MaxLevel = max(slot.MaxLevel, detail.MaxLevel)
+			}
+			slot.Chance = max(slot.Chance, min(chance, 100))
+		}
+		area.Slots = append(area.Slots, slot)
+	}
+	return area
+}
+
+// Roll picks the species of a wild encounter, weighted by each slot's
+// chance, and a level within the slot's range. It reports false when no
+// Pokemon live in the area.
+func (a Area) Roll(rng *rand.Rand) (Slot, int, bool) {
+	total := 0
+	for _, slot := range a.Slots {
+		total += slot.Chance
+	}
+	if total == 0 {
+		return Slot{}, 0, false
+	}
+	n := rng.Intn(total)
+	for _, slot := range a.Slots {
+		if n >= slot.Chance {
+			n -= slot.Chance
+			continue
+		}
+		return slot, slot.MinLevel + rng.Intn(max(0, slot.MaxLevel-slot.MinLevel)+1), true
+	}
+	return Slot{}, 0, false
+}
+
+// maxIV is the highest individual value a stat can roll.
+const maxIV = 31
+
+// NewWild rolls the individual values of a wild pokemon at level and
+// derives its stats from them, leaving out natures and effort values
+// until it is caught.
+func NewWild(pokemon pokeapi.Pokemon, level int, rng *rand.Rand) Wild {
+	wild := Wild{Species: pokemon.Name, Level: level, Stats: []Stat{}}
+	for _, stat := range pokemon.Stats {
+		iv := rng.Intn(maxIV + 1)
+		value := statValue(stat.Stat.Name, stat.BaseStat, iv, 0, level, Nature{})
+		wild.Stats = append(wild.Stats, Stat{Name: stat.Stat.Name, IV: iv, Value: value})
+	}
+	return wild
+}
+
+// Goto moves the trainer into area, leaving any wild Pokemon behind.
+func (t *Trainer) Goto(area Area) {
+	t.Location = area.Name
+	t.Area = area
+	t.Wild = nil
+}
+
+// Target returns the wild Pokemon a ball would be thrown at. species may
+// be empty; otherwise it has to name the wild Pokemon.
+func (t *Trainer) Target(species string) (Wild, error) {
+	if t.Location == "" {
+		return Wild{}, ErrNoLocation
+	}
+	if t.Wild == nil {
+		return Wild{}, ErrNoWild
+	}
+	if species != "" && species != t.Wild.Species {
+		return Wild{}, fmt.Errorf("%s %w, the wild Pokemon is a %s", species, ErrNotHere, t.Wild.Species)
+	}
+	return *t.Wild, nil
+}
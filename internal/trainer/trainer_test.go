@@ -0,0 +1,495 @@
+package trainer
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+const canalave = `{"pokemon_encounters": [
+	{"pokemon": {"name": "tentacool"}, "version_details": [
+		{"version": {"name": "diamond"}, "encounter_details": [
+			{"chance": 60, "min_level": 20, "max_level": 30},
+			{"chance": 30, "min_level": 25, "max_level": 35},
+			{"chance": 5, "min_level": 10, "max_level": 10}]},
+		{"version": {"name": "pearl"}, "encounter_details": [
+			{"chance": 60, "min_level": 20, "max_level": 30}]}]},
+	{"pokemon": {"name": "staryu"}, "version_details": [
+		{"version": {"name": "diamond"}, "encounter_details": [
+			{"chance": 15, "min_level": 15, "max_level": 25}]}]}]}`
+
+func TestNewArea(t *testing.T) {
+	var encounter pokeapi.EncounterResponse
+	if err := json.Unmarshal([]byte(canalave), &encounter); err != nil {
+		t.Fatal(err)
+	}
+	area := NewArea("canalave-city-area", encounter)
+
+	expected := []Slot{
+		{Species: "tentacool", Chance: 95, MinLevel: 10, MaxLevel: 35},
+		{Species: "staryu", Chance: 15, MinLevel: 15, MaxLevel: 25},
+	}
+	if len(area.Slots) != len(expected) {
+		t.Fatalf("Expected: %v, Got: %v", expected, area.Slots)
+	}
+	for i, slot := range area.Slots {
+		if slot != expected[i] {
+			t.Errorf("Expected: %v, Got: %v", expected[i], slot)
+		}
+	}
+}
+
+func TestRoll(t *testing.T) {
+	area := Area{Name: "canalave-city-area", Slots: []Slot{
+		{Species: "tentacool", Chance: 90, MinLevel: 20, MaxLevel: 30},
+		{Species: "staryu", Chance: 10, MinLevel: 15, MaxLevel: 15},
+	}}
+	rng := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for range 1000 {
+		slot, level, ok := area.Roll(rng)
+		if !ok {
+			t.Fatalf("expected a Pokemon to appear")
+		}
+		if level < slot.MinLevel || level > slot.MaxLevel {
+			t.Errorf("Expected a level from %v to %v, Got: %v", slot.MinLevel, slot.MaxLevel, level)
+		}
+		counts[slot.Species]++
+	}
+	if counts["staryu"] == 0 || counts["staryu"] > counts["tentacool"]/4 {
+		t.Errorf("expected staryu to be rare, got %v", counts)
+	}
+
+	if _, _, ok := (Area{}).Roll(rng); ok {
+		t.Errorf("expected an empty area to have no encounters")
+	}
+}
+
+func TestNewWild(t *testing.T) {
+	var pokemon pokeapi.Pokemon
+	body := `{"name": "tentacool", "stats": [{"base_stat": 40, "stat": {"name": "hp"}}, {"base_stat": 40, "stat": {"name": "attack"}}]}`
+	if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	wild := NewWild(pokemon, 50, rand.New(rand.NewSource(1)))
+	if wild.Species != "tentacool" || wild.Level != 50 || len(wild.Stats) != 2 {
+		t.Fatalf("unexpected wild pokemon: %+v", wild)
+	}
+	for _, stat := range wild.Stats {
+		if stat.IV < 0 || stat.IV > maxIV {
+			t.Errorf("Expected an IV from 0 to %v, Got: %v", maxIV, stat.IV)
+		}
+	}
+	hp, attack := wild.Stats[0], wild.Stats[1]
+	if hp.Value != (80+hp.IV)*50/100+60 {
+		t.Errorf("Expected hp: %v, Got: %v", (80+hp.IV)*50/100+60, hp.Value)
+	}
+	if attack.Value != (80+attack.IV)*50/100+5 {
+		t.Errorf("Expected attack: %v, Got: %v", (80+attack.IV)*50/100+5, attack.Value)
+	}
+}
+
+func TestTarget(t *testing.T) {
+	var trainer Trainer
+	if _, err := trainer.Target(""); !errors.Is(err, ErrNoLocation) {
+		t.Errorf("Expected: %v, Got: %v", ErrNoLocation, err)
+	}
+
+	trainer.Goto(Area{Name: "canalave-city-area"})
+	if _, err := trainer.Target(""); !errors.Is(err, ErrNoWild) {
+		t.Errorf("Expected: %v, Got: %v", ErrNoWild, err)
+	}
+
+	trainer.Wild = &Wild{Species: "staryu", Level: 15}
+	if wild, err := trainer.Target(""); err != nil || wild.Species != "staryu" {
+		t.Errorf("Expected staryu, Got: %v, %v", wild, err)
+	}
+	if _, err := trainer.Target("pikachu"); !errors.Is(err, ErrNotHere) {
+		t.Errorf("Expected: %v, Got: %v", ErrNotHere, err)
+	}
+
+	trainer.Goto(Area{Name: "eterna-city-area"})
+	if trainer.Wild != nil {
+		t.Errorf("expected the wild Pokemon to be left behind")
+	}
+}
+
+func TestParseBall(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "first word", input: "great", expected: "great-ball"},
+		{name: "item name", input: "ultra-ball", expected: "ultra-ball"},
+		{name: "unknown ball", input: "moon", expected: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ball, err := ParseBall(c.input)
+			if c.expected == "" {
+				if err == nil {
+					t.Errorf("expected an error, got %v", ball)
+				}
+				return
+			}
+			if err != nil || ball.Name != c.expected {
+				t.Errorf("Expected: %v, Got: %v, %v", c.expected, ball.Name, err)
+			}
+		})
+	}
+}
+
+func TestBagUse(t *testing.T) {
+	bag := Bag{"great-ball": 1}
+	great, _ := ParseBall("great")
+	if err := bag.Use(great); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bag["great-ball"] != 0 {
+		t.Errorf("Expected: 0, Got: %v", bag["great-ball"])
+	}
+	if err := bag.Use(great); !errors.Is(err, ErrOutOfBalls) {
+		t.Errorf("Expected: %v, Got: %v", ErrOutOfBalls, err)
+	}
+}
+
+func TestCatchChance(t *testing.T) {
+	cases := []struct {
+		name        string
+		captureRate int
+		ball        string
+		expected    float64
+	}{
+		{name: "common pokemon in a poke ball", captureRate: 255, ball: "poke", expected: 1.0 / 3},
+		{name: "great ball bonus", captureRate: 190, ball: "great", expected: 190 * 1.5 / 3 / 255},
+		{name: "legendary in an ultra ball", captureRate: 3, ball: "ultra", expected: 3 * 2.0 / 3 / 255},
+		{name: "master ball never fails", captureRate: 3, ball: "master", expected: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ball, _ := ParseBall(c.ball)
+			actual := CatchChance(c.captureRate, ball)
+			if math.Abs(actual-c.expected) > 1e-9 {
+				t.Errorf("Expected: %v, Got: %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCanEvolve(t *testing.T) {
+	level := func(n int) *int { return &n }
+	cases := []struct {
+		name      string
+		details   []pokeapi.EvolutionDetail
+		level     int
+		evolution Evolution
+		expected  string
+	}{
+		{
+			name:     "level reached",
+			details:  []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: level(16)}},
+			level:    16,
+			expected: "",
+		},
+		{
+			name:     "level too low",
+			details:  []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: level(16)}},
+			level:    15,
+			expected: "bulbasaur cannot evolve into ivysaur yet, it needs level 16",
+		},
+		{
+			name: "item supplied",
+			details: []pokeapi.EvolutionDetail{
+				{Trigger: pokeapi.NamedAPIResource{Name: "use-item"}, Item: &pokeapi.NamedAPIResource{Name: "leaf-stone"}},
+			},
+			evolution: Evolution{Item: "leaf-stone"},
+			expected:  "",
+		},
+		{
+			name: "trade holding an item",
+			details: []pokeapi.EvolutionDetail{
+				{Trigger: pokeapi.NamedAPIResource{Name: "trade"}, HeldItem: &pokeapi.NamedAPIResource{Name: "metal-coat"}},
+			},
+			evolution: Evolution{Trade: true},
+			expected:  "bulbasaur cannot evolve into ivysaur yet, it needs --item metal-coat",
+		},
+		{
+			name: "any one way is enough",
+			details: []pokeapi.EvolutionDetail{
+				{Trigger: pokeapi.NamedAPIResource{Name: "trade"}},
+				{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: level(40)},
+			},
+			level:    40,
+			expected: "",
+		},
+		{
+			name:      "time of day",
+			details:   []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, TimeOfDay: "night"}},
+			evolution: Evolution{Time: time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)},
+			expected:  "",
+		},
+		{
+			name:      "wrong time of day",
+			details:   []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, TimeOfDay: "night"}},
+			evolution: Evolution{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+			expected:  "bulbasaur cannot evolve into ivysaur yet, it needs nighttime",
+		},
+		{
+			name:     "gender",
+			details:  []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinLevel: level(30), Gender: level(1)}},
+			level:    30,
+			expected: "bulbasaur cannot evolve into ivysaur yet, it needs to be female",
+		},
+		{
+			name:     "attack compared with defense",
+			details:  []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, RelativePhysicalStats: level(1)}},
+			level:    20,
+			expected: "bulbasaur cannot evolve into ivysaur yet, it needs attack above defense",
+		},
+		{
+			name:     "unsupported conditions are never met",
+			details:  []pokeapi.EvolutionDetail{{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, MinHappiness: level(220)}},
+			expected: "bulbasaur cannot evolve into ivysaur, it needs happiness 220 (not supported)",
+		},
+		{
+			name: "unsupported next to a supported way",
+			details: []pokeapi.EvolutionDetail{
+				{Trigger: pokeapi.NamedAPIResource{Name: "level-up"}, Location: &pokeapi.NamedAPIResource{Name: "eterna-forest"}},
+				{Trigger: pokeapi.NamedAPIResource{Name: "use-item"}, Item: &pokeapi.NamedAPIResource{Name: "leaf-stone"}},
+			},
+			expected: "bulbasaur cannot evolve into ivysaur yet, it needs being at eterna-forest (not supported) or --item leaf-stone",
+		},
+	}
+	var pokemon pokeapi.Pokemon
+	body := `{"name": "bulbasaur", "stats": [{"base_stat": 49, "stat": {"name": "attack"}}, {"base_stat": 49, "stat": {"name": "defense"}}]}`
+	if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entry := Entry{Pokemon: pokemon, Level: c.level, Gender: "male"}
+			next := pokeapi.ChainLink{Species: pokeapi.NamedAPIResource{Name: "ivysaur"}, EvolutionDetails: c.details}
+			err := CanEvolve(entry, next, c.evolution)
+			if c.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != c.expected || !errors.Is(err, ErrCannotEvolve) {
+				t.Errorf("Expected: %v, Got: %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestFindLink(t *testing.T) {
+	chain := pokeapi.ChainLink{
+		Species: pokeapi.NamedAPIResource{Name: "oddish"},
+		EvolvesTo: []pokeapi.ChainLink{{
+			Species: pokeapi.NamedAPIResource{Name: "gloom"},
+			EvolvesTo: []pokeapi.ChainLink{
+				{Species: pokeapi.NamedAPIResource{Name: "vileplume"}},
+				{Species: pokeapi.NamedAPIResource{Name: "bellossom"}},
+			},
+		}},
+	}
+	link, ok := FindLink(chain, "gloom")
+	if !ok || len(link.EvolvesTo) != 2 {
+		t.Errorf("expected gloom with two evolutions, got %v", link)
+	}
+	if _, ok := FindLink(chain, "pikachu"); ok {
+		t.Errorf("expected pikachu not to be found")
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	var pokemon pokeapi.Pokemon
+	body := `{"name": "tentacool", "stats": [{"base_stat": 40, "stat": {"name": "hp"}}, {"base_stat": 40, "stat": {"name": "attack"}}]}`
+	if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	species := pokeapi.PokemonSpecies{GrowthRate: pokeapi.NamedAPIResource{Name: "slow"}, GenderRate: 8}
+	wild := Wild{Species: "tentacool", Level: 20, Stats: []Stat{{Name: "hp", IV: 7}, {Name: "attack", IV: 31}}}
+	entry := NewEntry(pokemon, species, wild, "pastoria-city-area", rand.New(rand.NewSource(1)))
+
+	if entry.Level != 20 || entry.XP != 10000 || entry.CaughtIn != "pastoria-city-area" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.IVs["hp"] != 7 || entry.IVs["attack"] != 31 || entry.EVs["attack"] != 0 || len(entry.EVs) != 2 {
+		t.Errorf("expected the wild IVs and no EVs, got %v and %v", entry.IVs, entry.EVs)
+	}
+	if entry.Gender != "female" {
+		t.Errorf("Expected: female, Got: %v", entry.Gender)
+	}
+	if entry.Nature == "" || len(entry.History) != 1 {
+		t.Errorf("expected a nature and the catch in the history, got %+v", entry)
+	}
+
+	species.GenderRate = -1
+	if entry := NewEntry(pokemon, species, wild, "", rand.New(rand.NewSource(1))); entry.Gender != "genderless" {
+		t.Errorf("Expected: genderless, Got: %v", entry.Gender)
+	}
+}
+
+func TestExperience(t *testing.T) {
+	cases := []struct {
+		growthRate string
+		level      int
+		expected   int
+	}{
+		{"medium", 100, 1000000},
+		{"fast", 100, 800000},
+		{"slow", 100, 1250000},
+		{"medium-slow", 100, 1059860},
+		{"medium-slow", 1, 0},
+		{"slow-then-very-fast", 100, 600000},
+		{"fast-then-very-slow", 100, 1640000},
+		{"unknown", 10, 1000},
+	}
+	for _, c := range cases {
+		if got := Experience(c.growthRate, c.level); got != c.expected {
+			t.Errorf("%s at %d: Expected: %v, Got: %v", c.growthRate, c.level, c.expected, got)
+		}
+	}
+}
+
+func TestEntryStats(t *testing.T) {
+	var pokemon pokeapi.Pokemon
+	body := `{"name": "tentacool", "stats": [{"base_stat": 40, "stat": {"name": "hp"}}, {"base_stat": 40, "stat": {"name": "attack"}}, {"base_stat": 100, "stat": {"name": "speed"}}]}`
+	if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	entry := Entry{
+		Pokemon: pokemon,
+		Level:   50,
+		IVs:     map[string]int{"hp": 31, "attack": 31, "speed": 31},
+		EVs:     map[string]int{"hp": 252},
+		Nature:  "timid",
+	}
+	expected := []int{(80+31+63)*50/100 + 60, ((80+31)*50/100 + 5) * 9 / 10, ((200+31)*50/100 + 5) * 11 / 10}
+	for i, stat := range entry.Stats() {
+		if stat.Value != expected[i] {
+			t.Errorf("%s: Expected: %v, Got: %v", stat.Name, expected[i], stat.Value)
+		}
+	}
+}
+
+func TestPCFind(t *testing.T) {
+	var pc PC
+	pc.Add(Entry{Pokemon: pokeapi.Pokemon{Name: "pikachu"}})
+	pc.Add(Entry{Pokemon: pokeapi.Pokemon{Name: "pikachu"}, Nickname: "sparky"})
+	pc.Add(Entry{Pokemon: pokeapi.Pokemon{Name: "eevee"}})
+
+	cases := []struct {
+		ref      string
+		expected int
+		err      error
+	}{
+		{ref: "eevee", expected: 3},
+		{ref: "sparky", expected: 2},
+		{ref: "#1", expected: 1},
+		{ref: "2", expected: 2},
+		{ref: "pikachu", err: ErrAmbiguous},
+		{ref: "ditto", err: ErrNotCaught},
+		{ref: "#9", err: ErrNotCaught},
+	}
+	for _, c := range cases {
+		entry, err := pc.Find(c.ref)
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Errorf("%s: Expected: %v, Got: %v", c.ref, c.err, err)
+			}
+			continue
+		}
+		if err != nil || entry.ID != c.expected {
+			t.Errorf("%s: Expected: #%v, Got: %+v, %v", c.ref, c.expected, entry, err)
+		}
+	}
+
+	clash := PC{}
+	clash.Add(Entry{Pokemon: pokeapi.Pokemon{Name: "pidgey"}, Nickname: "Rattata"})
+	clash.Add(Entry{Pokemon: pokeapi.Pokemon{Name: "rattata"}})
+	if _, err := clash.Find("rattata"); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("expected a nickname shared with a Pokemon name to be ambiguous, got %v", err)
+	}
+
+	if _, ok := pc.Release(3); !ok {
+		t.Fatalf("expected #3 to be released")
+	}
+	if _, ok := pc.Release(3); ok {
+		t.Errorf("expected #3 to be released only once")
+	}
+	if entry := pc.Add(Entry{Pokemon: pokeapi.Pokemon{Name: "eevee"}}); entry.ID != 4 {
+		t.Errorf("Expected id: 4, Got: %v", entry.ID)
+	}
+}
+
+func TestCheckNickname(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid bool
+	}{
+		{"sparky", true},
+		{"Mr.Mime2", true},
+		{"abcdefghijklm", false},
+		{"42", false},
+		{"#sparky", false},
+	}
+	for _, c := range cases {
+		if err := CheckNickname(c.name); (err == nil) != c.valid {
+			t.Errorf("%s: Expected valid: %v, Got: %v", c.name, c.valid, err)
+		}
+	}
+}
+
+func TestFindBall(t *testing.T) {
+	bag := Bag{}
+	rng := rand.New(rand.NewSource(1))
+	found := 0
+	for range 200 {
+		if ball, ok := bag.FindBall(rng); ok {
+			found++
+			if ball.Guaranteed || ball.Name == "ultra-ball" {
+				t.Errorf("expected only poke and great balls to be found, got %v", ball.Name)
+			}
+		}
+	}
+	if found == 0 || found > 100 {
+		t.Errorf("expected a ball on about one walk in %v, got %v in 200", findOdds, found)
+	}
+	if bag["poke-ball"]+bag["great-ball"] != found || bag["poke-ball"] < bag["great-ball"] {
+		t.Errorf("expected %v found balls in the bag, mostly poke balls, got %v", found, bag)
+	}
+}
+
+func TestEntryDefeat(t *testing.T) {
+	var defeated pokeapi.Pokemon
+	body := `{"name": "geodude", "base_experience": 60, "stats": [{"base_stat": 100, "effort": 1, "stat": {"name": "defense"}}]}`
+	if err := json.Unmarshal([]byte(body), &defeated); err != nil {
+		t.Fatal(err)
+	}
+	entry := Entry{Pokemon: pokeapi.Pokemon{Name: "pikachu"}, Level: 9, XP: 900, EVs: map[string]int{"defense": 252}}
+
+	xp, levels := entry.Defeat(defeated, 14, "medium")
+	if xp != 120 || entry.XP != 1020 {
+		t.Errorf("Expected 120 XP for a total of 1020, Got: %v for %v", xp, entry.XP)
+	}
+	if levels != 1 || entry.Level != 10 || len(entry.History) != 1 {
+		t.Errorf("expected to grow to level 10, got %v levels: %+v", levels, entry)
+	}
+	if entry.EVs["defense"] != 252 {
+		t.Errorf("Expected EVs capped at 252, Got: %v", entry.EVs["defense"])
+	}
+
+	old := Entry{Pokemon: pokeapi.Pokemon{Name: "pikachu"}, Level: 5}
+	if _, levels := old.Defeat(defeated, 2, "medium"); levels != 0 || old.XP != 125+17 {
+		t.Errorf("expected an entry without XP to start from its level, got %v levels and %v XP", levels, old.XP)
+	}
+}
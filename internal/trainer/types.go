@@ -0,0 +1,132 @@
+package trainer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+var (
+	ErrNoLocation   = errors.New("you are not in any area, use 'goto <area>' first")
+	ErrNoWild       = errors.New("there is no wild Pokemon around, use 'walk' to look for one")
+	ErrNotHere      = errors.New("is not here")
+	ErrOutOfBalls   = errors.New("you have run out of")
+	ErrCannotEvolve = errors.New("cannot evolve")
+	ErrNotCaught    = errors.New("is not in your pokedex")
+	ErrAmbiguous    = errors.New("you have more than one")
+)
+
+// Trainer is the player's place in the game world.
+type Trainer struct {
+	// Location is the location area the trainer stands in, or "" before
+	// the first goto. Area holds its encounters once they are loaded.
+	Location string
+	Area     Area
+	// Wild is the wild Pokemon in front of the trainer, if any.
+	Wild *Wild
+	Bag  Bag
+}
+
+// Bag counts the balls a trainer carries, keyed by item name.
+type Bag map[string]int
+
+// Ball is a kind of Poke Ball. Bonus multiplies the species' capture rate;
+// a Guaranteed ball never fails.
+type Ball struct {
+	Name       string
+	Display    string
+	Bonus      float64
+	Guaranteed bool
+}
+
+// Area is a location area reduced to what the game needs: which species
+// live there and how likely each one is to appear.
+type Area struct {
+	Name  string
+	Slots []Slot
+}
+
+// Slot is one species of an area. Chance is the percentage of encounters
+// it makes up in the version where it is most common.
+type Slot struct {
+	Species  string
+	Chance   int
+	MinLevel int
+	MaxLevel int
+}
+
+// Wild is a concrete wild Pokemon met while walking: a species at a level,
+// with its own randomly rolled stats.
+type Wild struct {
+	Species string
+	Level   int
+	Stats   []Stat
+	// Attempts counts the balls it has escaped from.
+	Attempts int
+}
+
+// Stat is one stat of a wild Pokemon. Value is derived from the species'
+// base stat, the individual value IV and the level.
+type Stat struct {
+	Name  string
+	IV    int
+	Value int
+}
+
+// Unknown stands in for the traits of Pokemon from saves that did not
+// track them, and UnknownXP for their experience.
+const (
+	Unknown   = "unknown"
+	UnknownXP = -1
+)
+
+// Entry is one caught Pokemon. A trainer can own several of a species,
+// each with its own ID and traits.
+type Entry struct {
+	ID       int             `json:"id"`
+	Pokemon  pokeapi.Pokemon `json:"pokemon"`
+	Nickname string          `json:"nickname,omitempty"`
+	Level    int             `json:"level"`
+	// XP is the total experience, which starts at what the species'
+	// growth rate needs for the level the Pokemon was caught at, or
+	// UnknownXP.
+	XP       int            `json:"xp"`
+	IVs      map[string]int `json:"ivs"`
+	EVs      map[string]int `json:"evs"`
+	Nature   string         `json:"nature"`
+	Gender   string         `json:"gender"`
+	Shiny    bool           `json:"shiny"`
+	CaughtAt time.Time      `json:"caught_at"`
+	CaughtIn string         `json:"caught_in"`
+	History  []Event        `json:"history"`
+}
+
+// PC is the box every caught Pokemon is sent to, in the order they were
+// caught. IDs are never reused, even after a release.
+type PC struct {
+	Pokemon []Entry `json:"pokemon"`
+	NextID  int     `json:"next_id"`
+}
+
+// Nature raises one stat by a tenth and lowers another. Neutral natures
+// raise and lower the same stat, which cancels out.
+type Nature struct {
+	Name      string
+	Increased string
+	Decreased string
+}
+
+// Event is something that happened to a caught Pokemon.
+type Event struct {
+	At          time.Time `json:"at"`
+	Description string    `json:"description"`
+}
+
+// Evolution is what the trainer brings to an evolution: the item used or
+// held, whether the Pokemon is being traded and the time it happens at.
+type Evolution struct {
+	Item  string
+	Trade bool
+	Time  time.Time
+}
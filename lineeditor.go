@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/peterh/liner"
+	"github.com/thmastin/pokedexcli/internal/nameindex"
+)
+
+// startLineEditor runs the REPL with line editing, tab completion and a
+// history that is kept in historyPath between sessions.
+func startLineEditor(st *state, historyPath string) {
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	line.SetCompleter(func(input string) []string {
+		return completeLine(st, input)
+	})
+	go st.index.Names(nameindex.Area)
+
+	if historyPath != "" {
+		if file, err := os.Open(historyPath); err == nil {
+			line.ReadHistory(file)
+			file.Close()
+		}
+		defer writeHistory(st, line, historyPath)
+	}
+
+	runREPL(st, func() (string, bool) {
+		for {
+			input, err := line.Prompt("Pokedex > ")
+			if errors.Is(err, liner.ErrPromptAborted) {
+				continue
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					st.warnf("failed to read input: %v", err)
+				}
+				return "", false
+			}
+			if strings.TrimSpace(input) != "" {
+				line.AppendHistory(input)
+			}
+			return input, true
+		}
+	})
+}
+
+func writeHistory(st *state, line *liner.State, historyPath string) {
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0o755); err != nil {
+		st.warnf("failed to save history: %v", err)
+		return
+	}
+	file, err := os.Create(historyPath)
+	if err != nil {
+		st.warnf("failed to save history: %v", err)
+		return
+	}
+	defer file.Close()
+	if _, err := line.WriteHistory(file); err != nil {
+		st.warnf("failed to save history: %v", err)
+	}
+}
+
+func defaultHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pokedexcli", "history")
+}
+
+// completeLine returns every full line that input can be completed to.
+// The first word completes to command names; later words use the
+// completer declared on the matching argSpec.
+func completeLine(st *state, input string) []string {
+	word := currentWord(input)
+	prefix := input[:len(input)-len(word)]
+	previous := strings.Fields(strings.ToLower(prefix))
+
+	var candidates []string
+	if len(previous) == 0 {
+		candidates = commandNames(st)
+	} else {
+		command, exists := commands[previous[0]]
+		if !exists {
+			return nil
+		}
+		position := 0
+		for i := 1; i < len(previous); i++ {
+			if !strings.HasPrefix(previous[i], "--") {
+				position++
+				continue
+			}
+			spec, ok := command.flag(strings.TrimPrefix(previous[i], "--"))
+			if ok && spec.value != "" && !strings.Contains(previous[i], "=") {
+				// The word being completed is this flag's value.
+				if i == len(previous)-1 {
+					return nil
+				}
+				i++
+			}
+		}
+		if position >= len(command.args) || command.args[position].complete == nil {
+			return nil
+		}
+		candidates = command.args[position].complete(st)
+	}
+
+	word = strings.ToLower(word)
+	completions := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, prefix+candidate)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+func currentWord(input string) string {
+	index := strings.LastIndexAny(input, " \t")
+	return input[index+1:]
+}
+
+func commandNames(_ *state) []string {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	return names
+}
+
+// areaNames completes area names seen this session and those in the name
+// index. Completion never waits on the network, so only an index that is
+// already loaded is used; startLineEditor loads it in the background.
+func areaNames(st *state) []string {
+	names := append(knownAreaNames(st), st.index.Loaded(nameindex.Area)...)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func knownAreaNames(st *state) []string {
+	names := []string{}
+	for name := range st.knownAreas {
+		names = append(names, name)
+	}
+	return names
+}
+
+func knownLocationNames(st *state) []string {
+	names := []string{}
+	for name := range st.knownLocations {
+		names = append(names, name)
+	}
+	return names
+}
+
+func knownRegionNames(st *state) []string {
+	names := []string{}
+	for name := range st.knownRegions {
+		names = append(names, name)
+	}
+	return names
+}
+
+func lastEncounterNames(st *state) []string {
+	return st.lastEncounter
+}
+
+func wildPokemonNames(st *state) []string {
+	if st.trainer.Wild == nil {
+		return []string{}
+	}
+	return []string{st.trainer.Wild.Species}
+}
+
+func pokedexNames(st *state) []string {
+	return st.pc.Names()
+}
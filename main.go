@@ -2,463 +2,106 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
-	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/peterh/liner"
 	"github.com/thmastin/pokedexcli/internal/pokeapi"
 	"github.com/thmastin/pokedexcli/internal/pokecache"
 )
 
-var commands map[string]cliCommand
-var mapConfig config
-var pokeCache pokecache.Cache
-var encounterBaseUrl string
-var catchBaseUrl string
-var pokedex map[string]pokeapi.Pokemon
-var rng *rand.Rand
-var catchAttempts map[string]int
-
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	startREPL((scanner))
-
-}
-
-func cleanInput(text string) []string {
-	lowerText := strings.ToLower(text)
-	splitText := strings.Fields(lowerText)
-	return splitText
-}
-
-func getFirstWord(words []string) string {
-	if len(words) == 0 {
-		return ""
+	offline := flag.Bool("offline", false, "serve PokeAPI responses from the fixture directory instead of the network")
+	record := flag.Bool("record", false, "save live PokeAPI responses into the fixture directory")
+	fixtureDir := flag.String("fixtures", defaultFixtureDir(), "directory of recorded PokeAPI responses")
+	commandLine := flag.String("c", "", "run the given commands, separated by ';', instead of starting the REPL")
+	keepGoing := flag.Bool("keep-going", false, "keep running a script after a command fails")
+	output := flag.String("output", outputText, "output format for command results: text or json")
+	prefetchEncounters := flag.Bool("prefetch-encounters", false, "also prefetch the encounters of every area listed by map")
+	flag.Parse()
+	if *offline && *record {
+		fmt.Fprintln(os.Stderr, "--offline and --record cannot be used together")
+		os.Exit(2)
 	}
-	return words[0]
-}
 
-func getSecondWord(words []string) string {
-	if len(words) < 2 {
-		return ""
+	st := newState(newClient(*offline, *record, *fixtureDir), os.Stdout, os.Stderr)
+	if err := st.setOutputFormat(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
-	return words[1]
-}
+	st.loadSave()
+	st.prefetchEncounters = *prefetchEncounters
 
-func displayOutput(word string) string {
-	if word == "" {
-		return "Please enter a command\n"
+	if *commandLine != "" {
+		script := strings.Join(splitCommands(*commandLine), "\n")
+		os.Exit(runScript(st, strings.NewReader(script), *keepGoing))
 	}
-	return fmt.Sprintf("Your command was: %s\n", word)
-}
-
-func startREPL(scanner *bufio.Scanner) {
-	for {
-		fmt.Print("Pokedex > ")
-		if !scanner.Scan() {
-			if err := scanner.Err(); err != nil {
-				fmt.Printf("Error reading input %v\n", err)
-			}
-			break
-		}
-		userInput, seconduserInput := processCommand(scanner.Text())
-		command, exists := commands[userInput]
-		if exists {
-			err := command.callback(seconduserInput)
-			if err != nil {
-				fmt.Printf("Error executing %v command: %v\n", userInput, err)
-			}
-		} else {
-			fmt.Println("Unknown command")
-		}
-
+	if flag.Arg(0) == "run" {
+		os.Exit(runScriptFile(st, flag.Args()[1:], *keepGoing))
 	}
-}
-
-func processCommand(userInput string) (string, string) {
-	inputCleaned := cleanInput(userInput)
-	firstWord := getFirstWord(inputCleaned)
-	secondWord := getSecondWord(inputCleaned)
-	return firstWord, secondWord
-}
-
-func commandExit(_ string) error {
-	fmt.Println("Closing the Pokedex... Goodbye!")
-	os.Exit(0)
-	return nil
-}
-
-type cliCommand struct {
-	name        string
-	description string
-	callback    func(string) error
-	config      *config
-}
-
-func commandHelp(_ string) error {
-	fmt.Println(helpMessage())
-	return nil
-}
-
-func helpMessage() string {
-	newMessage := `Welcome to the Pokedex!
-Usage:`
-	newMessage += "\n\n"
-	for _, value := range commands {
-		commandDescription := fmt.Sprintf("%v: %v\n", value.name, value.description)
-		newMessage += commandDescription
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unknown argument: %s\n", flag.Arg(0))
+		os.Exit(2)
 	}
 
-	return newMessage
-
-}
-
-func commandMap(_ string) error {
-	config := commands["map"].config
+	// Only interactive sessions prefetch; scripts fetch exactly what they
+	// ask for. Pending prefetches are cancelled when the REPL exits.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	st.client.StartPrefetch(ctx, prefetchWorkers)
 
-	var areaMap pokeapi.LocationAreaResponse
-	var err error
-
-	cacheKey := *config.Next
-	areaMap, err = fetchLocationAreaWithCache(cacheKey)
-	if err != nil {
-		return err
-	}
-	err = processLocationAreaResponse(areaMap, config)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func commandMapb(_ string) error {
-	config := commands["mapb"].config
-
-	var areaMap pokeapi.LocationAreaResponse
-	var err error
-
-	if config.Previous == nil {
-		fmt.Println("you're on the first page")
-		return nil
-	}
-	cacheKey := *config.Previous
-	areaMap, err = fetchLocationAreaWithCache(cacheKey)
-	if err != nil {
-		return err
-	}
-	err = processLocationAreaResponse(areaMap, config)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func commandExplore(seconduserInput string) error {
-	if seconduserInput == "" {
-		return fmt.Errorf("please enter an area name")
-	}
-
-	cacheKey := encounterBaseUrl + seconduserInput
-
-	cachedData, found := pokeCache.Get(cacheKey)
-	if found {
-		var encounter pokeapi.EncounterResponse
-		err := json.Unmarshal(cachedData, &encounter)
-		if err != nil {
-			return fmt.Errorf("invalid area: %s, please use the Pokedex 'map' command to see valid areas", seconduserInput)
-		}
-		err = processEncounterResponse(encounter, seconduserInput)
-		if err != nil {
-			return err
-		}
-		return nil
+	if isTerminal(os.Stdin) && liner.TerminalSupported() {
+		startLineEditor(st, defaultHistoryPath())
+		return
 	}
+	scanner := bufio.NewScanner(os.Stdin)
+	startREPL(st, scanner)
 
-	encounter, err := fetchEncounterWithCache(cacheKey, seconduserInput)
-	if err != nil {
-		return err
-	}
-	err = processEncounterResponse(encounter, seconduserInput)
-	if err != nil {
-		return err
-	}
-	return nil
 }
 
-func commandCatch(seconduserInput string) error {
-	var pokemon pokeapi.Pokemon
-	if seconduserInput == "" {
-		return fmt.Errorf("please enter a pokemon name")
-	}
+const prefetchWorkers = 4
 
-	cacheKey := catchBaseUrl + seconduserInput
+// cacheInterval is how long responses stay in memory. The name index is
+// rebuilt on the same schedule so it never outlives the listings behind it.
+const cacheInterval = 5 * time.Minute
 
-	cachedData, found := pokeCache.Get(cacheKey)
-	if found {
-		err := json.Unmarshal(cachedData, &pokemon)
+func newClient(offline, record bool, fixtureDir string) *pokeapi.Client {
+	cacheOpts := []pokecache.Option{}
+	// The disk cache is skipped when working with fixtures: offline runs
+	// must only see the bundle, and recording runs must hit the network.
+	if !offline && !record {
+		dir, err := os.UserCacheDir()
 		if err != nil {
-			return fmt.Errorf("invalid pokemon: %s, please use the Pokedex 'explore' command to see valid pokemon", seconduserInput)
-		}
-		err = processCatchResponse(pokemon, seconduserInput)
-		if err != nil {
-			return err
-		}
-		return nil
-	}
-
-	pokemon, err := fetchCatchWithCache(cacheKey, seconduserInput)
-	if err != nil {
-		return err
-	}
-	err = processCatchResponse(pokemon, seconduserInput)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func commandInspect(seconduserInput string) error {
-	pokemon, exists := pokedex[seconduserInput]
-	if exists {
-		inspectOutput(pokemon)
-	} else {
-		fmt.Printf("%v is not in your pokedex, you can try to catch it by using the 'catch' command\n", seconduserInput)
-	}
-	return nil
-}
-
-func inspectOutput(pokemon pokeapi.Pokemon) {
-	fmt.Printf("Name: %s\n", pokemon.Name)
-	fmt.Printf("Height: %v\n", pokemon.Height)
-	fmt.Printf("Weight: %v\n", pokemon.Weight)
-	fmt.Println("Stats:")
-	for _, v := range pokemon.Stats {
-		fmt.Printf("  -%s: %v\n", v.Stat.Name, v.BaseStat)
-	}
-	fmt.Println("Types:")
-	for _, v := range pokemon.Types {
-		fmt.Printf("  -%s\n", v.Type.Name)
-	}
-}
-
-func commandPokedex(_ string) error {
-	if len(pokedex) > 0 {
-		fmt.Println("Your Pokedex:")
-		for _, v := range pokedex {
-			fmt.Printf("  - %s\n", v.Name)
-		}
-	} else {
-		fmt.Println("You do not have any Pokemon in your Pokedex. Use the 'catch' command to catch your first one!")
-	}
-	return nil
-}
-
-type config struct {
-	Next     *string
-	Previous *string
-	Results  []pokeapi.LocationArea
-}
-
-func processLocationAreaResponse(areaMap pokeapi.LocationAreaResponse, config *config) error {
-	if areaMap.Next != nil {
-		config.Next = areaMap.Next
-	} else {
-		config.Next = nil
-	}
-	if areaMap.Previous != nil {
-		config.Previous = areaMap.Previous
-	} else {
-		config.Previous = nil
-	}
-	config.Results = areaMap.Results
-	for _, result := range areaMap.Results {
-		fmt.Println(result.Name)
-	}
-	return nil
-}
-
-func processEncounterResponse(encounter pokeapi.EncounterResponse, areaName string) error {
-	if encounter.PokemonEncounters == nil {
-		fmt.Printf("No Pokemon found in %v", areaName)
-		return nil
-	}
-
-	fmt.Printf("Exploring %v...\n", areaName)
-	fmt.Println("Found Pokemon:")
-	for _, encounterEntry := range encounter.PokemonEncounters {
-		fmt.Printf(" - %v\n", encounterEntry.Pokemon.Name)
-	}
-	return nil
-}
-
-func processCatchResponse(pokemon pokeapi.Pokemon, pokemonName string) error {
-	if _, ok := pokedex[pokemonName]; ok {
-		return fmt.Errorf("you've already caught %s", pokemonName)
-	} else {
-		fmt.Printf("Throwing a Pokeball at %s...", pokemonName)
-		pokemonExperience := pokemon.BaseExperience
-		if catchAttempts[pokemon.Name] == 2 {
-			pokemonCatch(pokemon, pokemonName)
+			fmt.Fprintf(os.Stderr, "Warning: responses will only be cached in memory: %v\n", err)
 		} else {
-			chance := rng.Float64() * 100
-			if chance > float64(pokemonExperience/2) {
-				pokemonCatch(pokemon, pokemonName)
-			} else {
-				fmt.Printf("%s escaped!\n", pokemonName)
-				catchAttempts[pokemon.Name] += 1
-				fmt.Printf("Attempted catch: %v\n", catchAttempts[pokemon.Name])
-
-			}
+			cacheOpts = append(cacheOpts, pokecache.WithDisk(filepath.Join(dir, "pokedexcli"), 24*time.Hour))
 		}
-		return nil
 	}
-}
-
-func pokemonCatch(catch pokeapi.Pokemon, pokemonName string) {
-	pokedex[catch.Name] = catch
-	fmt.Printf("%s was caught!\n", pokemonName)
-}
-
-func fetchLocationAreaWithCache(apiURL string) (pokeapi.LocationAreaResponse, error) {
-	var areaMap pokeapi.LocationAreaResponse
-	var err error
 
-	cachedData, found := pokeCache.Get(apiURL)
-	if found {
-		err = json.Unmarshal(cachedData, &areaMap)
-		if err != nil {
-			return pokeapi.LocationAreaResponse{}, fmt.Errorf("error unmarshaling cached data: %w", err)
-		}
-	} else {
-
-		areaMap, err = pokeapi.FetchLocationAreas(apiURL)
-		if err != nil {
-			return pokeapi.LocationAreaResponse{}, err
-		}
-
-		dataToCache, marshalErr := json.Marshal(areaMap)
-		if marshalErr != nil {
-			return pokeapi.LocationAreaResponse{}, fmt.Errorf("error marshaling data for cache: %w", marshalErr)
-		}
-		pokeCache.Add(apiURL, dataToCache)
+	clientOpts := []pokeapi.Option{pokeapi.WithCache(pokecache.NewCache(cacheInterval, cacheOpts...))}
+	if offline {
+		clientOpts = append(clientOpts, pokeapi.WithOffline(fixtureDir))
 	}
-	return areaMap, nil
-
-}
-
-func fetchEncounterWithCache(apiURL string, areaName string) (pokeapi.EncounterResponse, error) {
-	var encounter pokeapi.EncounterResponse
-	var err error
-
-	cachedData, found := pokeCache.Get(apiURL)
-	if found {
-		err = json.Unmarshal(cachedData, &encounter)
-		if err != nil {
-			return pokeapi.EncounterResponse{}, fmt.Errorf("error unmarshaling cached data: %w", err)
-		}
-	} else {
-		encounter, err = pokeapi.FetchEncounter(apiURL, areaName)
-		if err != nil {
-			return pokeapi.EncounterResponse{}, err
-		}
-
-		dataToCache, marshalErr := json.Marshal(encounter)
-		if marshalErr != nil {
-			return pokeapi.EncounterResponse{}, fmt.Errorf("error marshaling data for cache: %w", marshalErr)
-		}
-		pokeCache.Add(apiURL, dataToCache)
+	if record {
+		clientOpts = append(clientOpts, pokeapi.WithRecording(fixtureDir))
 	}
-	return encounter, nil
+	return pokeapi.NewClient(clientOpts...)
 }
 
-func fetchCatchWithCache(apiURL string, pokemonName string) (pokeapi.Pokemon, error) {
-	var pokemon pokeapi.Pokemon
-	var err error
-
-	cachedData, found := pokeCache.Get(apiURL)
-	if found {
-		err = json.Unmarshal(cachedData, &pokemon)
-		if err != nil {
-			return pokeapi.Pokemon{}, fmt.Errorf("error unmarshaling cached data: %w", err)
-		}
-	} else {
-		pokemon, err = pokeapi.FetchPokemon(apiURL, pokemonName)
-		if err != nil {
-			return pokeapi.Pokemon{}, err
-		}
-		dataToCache, marshalErr := json.Marshal(pokemon)
-		if marshalErr != nil {
-			return pokeapi.Pokemon{}, fmt.Errorf("error marshaling data for cache: %w", marshalErr)
-		}
-		pokeCache.Add(apiURL, dataToCache)
-	}
-	return pokemon, nil
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
-func init() {
-	commands = map[string]cliCommand{
-		"help": {
-			name:        "help",
-			description: "Displays this help message",
-			callback:    commandHelp,
-			config:      nil,
-		},
-		"exit": {
-			name:        "exit",
-			description: "Exit the Pokedex",
-			callback:    commandExit,
-			config:      nil,
-		},
-		"map": {
-			name:        "map",
-			description: "Displays 20 location areas",
-			callback:    commandMap,
-			config:      &mapConfig,
-		},
-		"mapb": {
-			name:        "mapb",
-			description: "Displays previous 20 location areas",
-			callback:    commandMapb,
-			config:      &mapConfig,
-		},
-		"explore": {
-			name:        "explore <area_name>",
-			description: "Displays the poke youman you can find in the area",
-			callback:    commandExplore,
-			config:      nil,
-		},
-		"catch": {
-			name:        "catch <pokemon_name>",
-			description: "Attempts to catch a Pokemon",
-			callback:    commandCatch,
-			config:      nil,
-		},
-		"inspect": {
-			name:        "inspect <pokemon_name>",
-			description: "Shows you information about the Pokemon",
-			callback:    commandInspect,
-			config:      nil,
-		},
-		"pokedex": {
-			name:        "pokedex",
-			description: "Shows you the Pokemon in your Pokedex",
-			callback:    commandPokedex,
-			config:      nil,
-		},
-	}
-	mapStart := "https://pokeapi.co/api/v2/location-area/"
-	mapConfig = config{
-		Next:     &mapStart,
-		Previous: nil,
-		Results:  []pokeapi.LocationArea{},
+func defaultFixtureDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "fixtures"
 	}
-	pokeCache = pokecache.NewCache(5 * time.Minute)
-	encounterBaseUrl = "https://pokeapi.co/api/v2/location-area/"
-	catchBaseUrl = "https://pokeapi.co/api/v2/pokemon/"
-	rng = rand.New(rand.NewSource(time.Now().UnixNano()))
-	pokedex = make(map[string]pokeapi.Pokemon)
-	catchAttempts = make(map[string]int)
+	return filepath.Join(dir, "pokedexcli", "fixtures")
 }
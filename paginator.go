@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+)
+
+// listingSpec declares a paged PokeAPI listing. Every spec gets its own
+// paginator and, when command is set, a REPL command to page through it.
+type listingSpec struct {
+	key         string
+	endpoint    string
+	command     string
+	description string
+}
+
+var listingSpecs = []listingSpec{
+	{key: "areas", endpoint: "location-area"},
+	{key: "locations", endpoint: "location", command: "locations", description: "Pages through every location"},
+	{key: "regions", endpoint: "region", command: "regions", description: "Pages through every region"},
+	{key: "pokemon", endpoint: "pokemon", command: "pokemon", description: "Pages through every Pokemon"},
+	{key: "items", endpoint: "item", command: "items", description: "Pages through every item"},
+	{key: "moves", endpoint: "move", command: "moves", description: "Pages through every move"},
+}
+
+// pageMoves are the words a paginator accepts besides a page number.
+var pageMoves = []string{"next", "prev", "first", "last"}
+
+// paginator is the cursor of one listing. Pages are numbered from 1; page
+// is 0 until the first page has been shown.
+type paginator struct {
+	label    string
+	endpoint string
+	page     int
+	pageSize int
+	count    int
+	results  []pokeapi.NamedAPIResource
+}
+
+const defaultPageSize = 20
+
+func newPaginators() map[string]*paginator {
+	paginators := make(map[string]*paginator)
+	for _, spec := range listingSpecs {
+		paginators[spec.key] = &paginator{label: spec.key, endpoint: spec.endpoint, pageSize: defaultPageSize}
+	}
+	return paginators
+}
+
+// pages is the number of pages at the current size, once count is known.
+func (p *paginator) pages() int {
+	return max(1, (p.count+p.pageSize-1)/p.pageSize)
+}
+
+// resize changes the page size, renumbering the current page so it holds
+// the first result that was shown. It returns the page to show.
+func (p *paginator) resize(size int) int {
+	if p.page == 0 {
+		p.pageSize = size
+		return 1
+	}
+	p.page = (p.page-1)*p.pageSize/size + 1
+	p.pageSize = size
+	return p.page
+}
+
+// target resolves "next", "prev", "first", "last" or a page number into
+// the page to show. ok is false when there is nowhere to move.
+func (p *paginator) target(move string) (page int, ok bool, err error) {
+	switch move {
+	case "", "next":
+		if p.page > 0 && p.page >= p.pages() {
+			return 0, false, nil
+		}
+		return p.page + 1, true, nil
+	case "prev":
+		if p.page <= 1 {
+			return 0, false, nil
+		}
+		return p.page - 1, true, nil
+	case "first":
+		return 1, true, nil
+	case "last":
+		return -1, true, nil
+	}
+	n, err := strconv.Atoi(move)
+	if err != nil || n < 1 {
+		return 0, false, fmt.Errorf("page must be a positive number or one of next, prev, first, last")
+	}
+	return n, true, nil
+}
+
+// load fetches and shows a page. Page -1 is the last page, which needs
+// the listing's count before its offset is known.
+func (p *paginator) load(client *pokeapi.Client, page int) (listResult, error) {
+	if page == -1 {
+		if p.page == 0 {
+			list, err := client.ListPage(p.endpoint, 0, p.pageSize)
+			if err != nil {
+				return listResult{}, err
+			}
+			p.count = list.Count
+		}
+		page = p.pages()
+	}
+	list, err := client.ListPage(p.endpoint, (page-1)*p.pageSize, p.pageSize)
+	if err != nil {
+		return listResult{}, err
+	}
+	p.count = list.Count
+	if len(list.Results) == 0 && page > 1 {
+		return listResult{}, fmt.Errorf("there are only %d pages of %d %s", p.pages(), p.pageSize, p.label)
+	}
+	p.page = page
+	p.results = list.Results
+
+	res := listResult{
+		Label:       p.label,
+		Names:       []string{},
+		Page:        p.page,
+		Pages:       p.pages(),
+		PageSize:    p.pageSize,
+		Total:       list.Count,
+		HasNext:     list.Next != nil,
+		HasPrevious: list.Previous != nil,
+	}
+	for _, resource := range list.Results {
+		res.Names = append(res.Names, resource.Name)
+	}
+	return res, nil
+}
+
+// commandPage is the callback shared by every listing command.
+func commandPage(key string) func(*state, commandArgs) (result, error) {
+	return func(st *state, args commandArgs) (result, error) {
+		return showPage(st, key, args.arg(0), args)
+	}
+}
+
+func showPage(st *state, key, move string, args commandArgs) (result, error) {
+	p := st.listings[key]
+	page, ok := 0, false
+	if args.hasFlag("size") {
+		size, err := strconv.Atoi(args.flag("size"))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("--size must be a positive number")
+		}
+		// A new size on its own redraws the current page.
+		resized := p.resize(size)
+		if move == "" {
+			page, ok = resized, true
+		}
+	}
+	if !ok {
+		var err error
+		page, ok, err = p.target(move)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		if move == "prev" {
+			return messageResult{Message: "you're on the first page"}, nil
+		}
+		return messageResult{Message: "you're on the last page"}, nil
+	}
+	res, err := p.load(st.client, page)
+	if err != nil {
+		return nil, err
+	}
+	if known := st.knownNames(key); known != nil {
+		for _, name := range res.Names {
+			known[name] = true
+		}
+	}
+	st.prefetchAround(p, res.Names)
+	return res, nil
+}
+
+// prefetchAround warms the pages either side of the one just shown, and
+// optionally the encounters of each area on it.
+func (st *state) prefetchAround(p *paginator, names []string) {
+	urls := []string{}
+	if p.page < p.pages() {
+		urls = append(urls, st.client.PageURL(p.endpoint, p.page*p.pageSize, p.pageSize))
+	}
+	if p.page > 1 {
+		urls = append(urls, st.client.PageURL(p.endpoint, (p.page-2)*p.pageSize, p.pageSize))
+	}
+	if p.endpoint == "location-area" && st.prefetchEncounters {
+		for _, name := range names {
+			urls = append(urls, st.client.EndpointURL("location-area")+name)
+		}
+	}
+	st.client.Prefetch(urls...)
+}
+
+func pageMoveNames(_ *state) []string {
+	return pageMoves
+}
+
+var pageFlags = []flagSpec{
+	{name: "size", value: "n", description: "Show n results per page (default 20)"},
+}
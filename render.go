@@ -0,0 +1,586 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/nameindex"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// result is the structured value a command produces. Text output is
+// rendered by the result itself; JSON output serializes it as-is, so the
+// json tags below are the stable schema tooling relies on.
+type result interface {
+	renderText(w io.Writer)
+}
+
+func (st *state) setOutputFormat(format string) error {
+	switch format {
+	case outputText, outputJSON:
+		st.outputFormat = format
+		return nil
+	default:
+		return fmt.Errorf("unknown output format: %s, use text or json", format)
+	}
+}
+
+func (st *state) render(res result) error {
+	if st.outputFormat == outputJSON {
+		return writeJSON(st.out, res)
+	}
+	res.renderText(st.out)
+	return nil
+}
+
+// renderError reports a failed command on w, which is st.out in the REPL
+// and st.errOut for scripts.
+func (st *state) renderError(w io.Writer, err error) {
+	if st.outputFormat == outputJSON {
+		writeJSON(w, errorResult{Error: err.Error()})
+		return
+	}
+	fmt.Fprintln(w, err)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(body))
+	return err
+}
+
+type errorResult struct {
+	Error string `json:"error"`
+}
+
+type messageResult struct {
+	Message string `json:"message"`
+}
+
+func (r messageResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, r.Message)
+}
+
+type commandInfo struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+type helpResult struct {
+	Commands []commandInfo `json:"commands"`
+}
+
+func (r helpResult) renderText(w io.Writer) {
+	fmt.Fprint(w, "Welcome to the Pokedex!\nUsage:\n\n")
+	for _, command := range r.Commands {
+		fmt.Fprintf(w, "%v: %v\n", command.Usage, command.Description)
+	}
+	fmt.Fprintln(w)
+}
+
+type commandHelpResult struct {
+	Name        string        `json:"name"`
+	Usage       string        `json:"usage"`
+	Description string        `json:"description"`
+	Flags       []commandInfo `json:"flags"`
+}
+
+func (r commandHelpResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n", r.Usage, r.Description)
+	if len(r.Flags) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	for _, flag := range r.Flags {
+		fmt.Fprintf(w, "  %s: %s\n", flag.Usage, flag.Description)
+	}
+}
+
+// listResult is one page of a listing. In JSON the names are keyed by the
+// listing's label, e.g. {"areas": [...], "page": 1, ...}.
+type listResult struct {
+	Label       string
+	Names       []string
+	Page        int
+	Pages       int
+	PageSize    int
+	Total       int
+	HasNext     bool
+	HasPrevious bool
+}
+
+func (r listResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		r.Label:        r.Names,
+		"page":         r.Page,
+		"pages":        r.Pages,
+		"page_size":    r.PageSize,
+		"total":        r.Total,
+		"has_next":     r.HasNext,
+		"has_previous": r.HasPrevious,
+	})
+}
+
+func (r listResult) renderText(w io.Writer) {
+	for _, name := range r.Names {
+		fmt.Fprintln(w, name)
+	}
+	fmt.Fprintf(w, "page %d of %d (%d %s total)\n", r.Page, r.Pages, r.Total, r.Label)
+}
+
+// encounterResult lists the Pokemon of an area. Encounters are only filled
+// in when a game version was asked for.
+type encounterResult struct {
+	Area       string          `json:"area"`
+	Version    string          `json:"version,omitempty"`
+	Pokemon    []string        `json:"pokemon"`
+	Encounters []encounterInfo `json:"encounters,omitempty"`
+}
+
+type encounterInfo struct {
+	Pokemon    string   `json:"pokemon"`
+	Method     string   `json:"method"`
+	Chance     int      `json:"chance"`
+	MinLevel   int      `json:"min_level"`
+	MaxLevel   int      `json:"max_level"`
+	Conditions []string `json:"conditions"`
+}
+
+func (r encounterResult) renderText(w io.Writer) {
+	if len(r.Pokemon) == 0 {
+		fmt.Fprintf(w, "No Pokemon found in %v\n", r.Area)
+		return
+	}
+	if r.Version == "" {
+		fmt.Fprintf(w, "Exploring %v...\n", r.Area)
+		fmt.Fprintln(w, "Found Pokemon:")
+		for _, name := range r.Pokemon {
+			fmt.Fprintf(w, " - %v\n", name)
+		}
+		return
+	}
+	fmt.Fprintf(w, "Exploring %v in %v...\n", r.Area, r.Version)
+	fmt.Fprintln(w, "Found Pokemon:")
+	for _, row := range r.Encounters {
+		fmt.Fprintf(w, " - %v: %v%% %v, %v", row.Pokemon, row.Chance, row.Method, levelRange(row.MinLevel, row.MaxLevel))
+		if len(row.Conditions) > 0 {
+			fmt.Fprintf(w, " (%v)", strings.Join(row.Conditions, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func levelRange(minLevel, maxLevel int) string {
+	if minLevel == maxLevel {
+		return fmt.Sprintf("lv %d", minLevel)
+	}
+	return fmt.Sprintf("lv %d-%d", minLevel, maxLevel)
+}
+
+// whereResult lists where a Pokemon can be met, sorted so rows of the same
+// version and method are next to each other.
+type whereResult struct {
+	Pokemon    string      `json:"pokemon"`
+	Encounters []whereInfo `json:"encounters"`
+}
+
+type whereInfo struct {
+	Version    string   `json:"version"`
+	Method     string   `json:"method"`
+	Area       string   `json:"area"`
+	Chance     int      `json:"chance"`
+	MinLevel   int      `json:"min_level"`
+	MaxLevel   int      `json:"max_level"`
+	Conditions []string `json:"conditions"`
+}
+
+func (r whereResult) renderText(w io.Writer) {
+	if len(r.Encounters) == 0 {
+		fmt.Fprintf(w, "%v cannot be found in the wild\n", r.Pokemon)
+		return
+	}
+	fmt.Fprintf(w, "%v can be found in:\n", r.Pokemon)
+	for i, row := range r.Encounters {
+		if i == 0 || row.Version != r.Encounters[i-1].Version {
+			fmt.Fprintf(w, "%v:\n", row.Version)
+		}
+		if i == 0 || row.Version != r.Encounters[i-1].Version || row.Method != r.Encounters[i-1].Method {
+			fmt.Fprintf(w, "  %v:\n", row.Method)
+		}
+		fmt.Fprintf(w, "   - %v: %v%%, %v", row.Area, row.Chance, levelRange(row.MinLevel, row.MaxLevel))
+		if len(row.Conditions) > 0 {
+			fmt.Fprintf(w, " (%v)", strings.Join(row.Conditions, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+type regionResult struct {
+	Region     string   `json:"region"`
+	Generation string   `json:"generation"`
+	Locations  []string `json:"locations"`
+}
+
+func (r regionResult) renderText(w io.Writer) {
+	if len(r.Locations) == 0 {
+		fmt.Fprintf(w, "No locations found in %v\n", r.Region)
+		return
+	}
+	fmt.Fprintf(w, "Locations in %v (%v):\n", r.Region, r.Generation)
+	for _, name := range r.Locations {
+		fmt.Fprintf(w, " - %v\n", name)
+	}
+}
+
+type locationResult struct {
+	Location string   `json:"location"`
+	Region   string   `json:"region"`
+	Areas    []string `json:"areas"`
+}
+
+func (r locationResult) renderText(w io.Writer) {
+	if len(r.Areas) == 0 {
+		fmt.Fprintf(w, "No areas to explore in %v\n", r.Location)
+		return
+	}
+	fmt.Fprintf(w, "Areas in %v (%v):\n", r.Location, r.Region)
+	for _, name := range r.Areas {
+		fmt.Fprintf(w, " - %v\n", name)
+	}
+}
+
+type wildResult struct {
+	Pokemon string     `json:"pokemon"`
+	Level   int        `json:"level"`
+	Area    string     `json:"area"`
+	Stats   []statInfo `json:"stats"`
+	// Found is the ball picked up on the way, if any.
+	Found string `json:"found,omitempty"`
+}
+
+func (r wildResult) renderText(w io.Writer) {
+	if r.Found != "" {
+		fmt.Fprintf(w, "You found %s!\n", withArticle(r.Found))
+	}
+	fmt.Fprintf(w, "A wild %s (lv %d) appeared in %s!\n", r.Pokemon, r.Level, r.Area)
+	for _, stat := range r.Stats {
+		fmt.Fprintf(w, "  -%s: %v\n", stat.Name, stat.Value)
+	}
+}
+
+type catchResult struct {
+	Pokemon string `json:"pokemon"`
+	Ball    string `json:"ball"`
+	Caught  bool   `json:"caught"`
+	// Attempts counts the balls this wild Pokemon has escaped from.
+	Attempts int `json:"attempts"`
+}
+
+func (r catchResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "Throwing %s at %s...", withArticle(r.Ball), r.Pokemon)
+	if r.Caught {
+		fmt.Fprintf(w, "%s was caught!\n", r.Pokemon)
+		return
+	}
+	fmt.Fprintf(w, "%s escaped!\n", r.Pokemon)
+	fmt.Fprintf(w, "Attempted catch: %v\n", r.Attempts)
+}
+
+func withArticle(noun string) string {
+	if noun != "" && strings.ContainsRune("AEIOU", rune(noun[0])) {
+		return "an " + noun
+	}
+	return "a " + noun
+}
+
+type battleResult struct {
+	Pokemon      string `json:"pokemon"`
+	Wild         string `json:"wild"`
+	XP           int    `json:"xp"`
+	Level        int    `json:"level"`
+	LevelsGained int    `json:"levels_gained"`
+}
+
+func (r battleResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "%s defeated the wild %s and gained %d XP!\n", r.Pokemon, r.Wild, r.XP)
+	if r.LevelsGained > 0 {
+		fmt.Fprintf(w, "%s grew to level %d!\n", r.Pokemon, r.Level)
+	}
+}
+
+type bagResult struct {
+	Balls []ballInfo `json:"balls"`
+}
+
+type ballInfo struct {
+	Name    string `json:"name"`
+	Display string `json:"display"`
+	Count   int    `json:"count"`
+}
+
+func (r bagResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "Your bag:")
+	for _, ball := range r.Balls {
+		fmt.Fprintf(w, "  - %s: %d\n", ball.Display, ball.Count)
+	}
+}
+
+type statInfo struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+type inspectResult struct {
+	ID       int            `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Nickname string         `json:"nickname,omitempty"`
+	Height   int            `json:"height"`
+	Weight   int            `json:"weight"`
+	Stats    []statInfo     `json:"stats"`
+	Types    []string       `json:"types"`
+	Level    int            `json:"level,omitempty"`
+	XP       int            `json:"xp,omitempty"`
+	Nature   string         `json:"nature,omitempty"`
+	Gender   string         `json:"gender,omitempty"`
+	Shiny    bool           `json:"shiny,omitempty"`
+	CaughtAt time.Time      `json:"caught_at,omitzero"`
+	CaughtIn string         `json:"caught_in,omitempty"`
+	IVs      []statInfo     `json:"ivs,omitempty"`
+	EVs      []statInfo     `json:"evs,omitempty"`
+	History  []historyInfo  `json:"history,omitempty"`
+	Species  *speciesResult `json:"species,omitempty"`
+}
+
+type historyInfo struct {
+	At          time.Time `json:"at"`
+	Description string    `json:"description"`
+}
+
+func (r inspectResult) renderText(w io.Writer) {
+	if r.ID > 0 {
+		fmt.Fprintf(w, "ID: #%d\n", r.ID)
+	}
+	fmt.Fprintf(w, "Name: %s\n", r.Name)
+	if r.Nickname != "" {
+		fmt.Fprintf(w, "Nickname: %s\n", r.Nickname)
+	}
+	fmt.Fprintf(w, "Height: %v\n", r.Height)
+	fmt.Fprintf(w, "Weight: %v\n", r.Weight)
+	fmt.Fprintln(w, "Stats:")
+	for _, stat := range r.Stats {
+		fmt.Fprintf(w, "  -%s: %v\n", stat.Name, stat.Value)
+	}
+	fmt.Fprintln(w, "Types:")
+	for _, name := range r.Types {
+		fmt.Fprintf(w, "  -%s\n", name)
+	}
+	if r.Level > 0 {
+		fmt.Fprintf(w, "Level: %v\n", r.Level)
+		if r.XP < 0 {
+			fmt.Fprintln(w, "XP: unknown")
+		} else {
+			fmt.Fprintf(w, "XP: %v\n", r.XP)
+		}
+	}
+	if r.Nature != "" {
+		fmt.Fprintf(w, "Nature: %s\n", r.Nature)
+	}
+	if r.Gender != "" {
+		fmt.Fprintf(w, "Gender: %s\n", r.Gender)
+	}
+	if r.Shiny {
+		fmt.Fprintln(w, "Shiny: yes")
+	}
+	if !r.CaughtAt.IsZero() || r.CaughtIn != "" {
+		when := "at an unknown time"
+		if !r.CaughtAt.IsZero() {
+			when = r.CaughtAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "Caught: %s", when)
+		if r.CaughtIn != "" {
+			fmt.Fprintf(w, " in %s", r.CaughtIn)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(r.IVs) > 0 {
+		fmt.Fprintln(w, "IVs:")
+		for _, stat := range r.IVs {
+			fmt.Fprintf(w, "  -%s: %v\n", stat.Name, stat.Value)
+		}
+		fmt.Fprintln(w, "EVs:")
+		for _, stat := range r.EVs {
+			fmt.Fprintf(w, "  -%s: %v\n", stat.Name, stat.Value)
+		}
+	}
+	if len(r.History) > 0 {
+		fmt.Fprintln(w, "History:")
+		for _, event := range r.History {
+			fmt.Fprintf(w, "  -%s: %s\n", event.At.Format("2006-01-02 15:04"), event.Description)
+		}
+	}
+	if r.Species != nil {
+		r.Species.renderText(w)
+	}
+}
+
+type speciesResult struct {
+	Name          string `json:"name"`
+	Genus         string `json:"genus"`
+	CaptureRate   int    `json:"capture_rate"`
+	BaseHappiness int    `json:"base_happiness"`
+	GrowthRate    string `json:"growth_rate"`
+	Baby          bool   `json:"baby"`
+	Legendary     bool   `json:"legendary"`
+	Mythical      bool   `json:"mythical"`
+	FlavorText    string `json:"flavor_text"`
+}
+
+func (r speciesResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "Species: %s\n", r.Name)
+	if r.Genus != "" {
+		fmt.Fprintf(w, "Genus: %s\n", r.Genus)
+	}
+	fmt.Fprintf(w, "Capture rate: %v\n", r.CaptureRate)
+	fmt.Fprintf(w, "Base happiness: %v\n", r.BaseHappiness)
+	fmt.Fprintf(w, "Growth rate: %s\n", r.GrowthRate)
+	switch {
+	case r.Mythical:
+		fmt.Fprintln(w, "Mythical Pokemon")
+	case r.Legendary:
+		fmt.Fprintln(w, "Legendary Pokemon")
+	case r.Baby:
+		fmt.Fprintln(w, "Baby Pokemon")
+	}
+	if r.FlavorText != "" {
+		fmt.Fprintln(w, r.FlavorText)
+	}
+}
+
+type evolutionResult struct {
+	Chain evolutionNode `json:"chain"`
+}
+
+// evolutionNode is one stage of an evolution chain. Conditions lists the
+// ways the previous stage evolves into it.
+type evolutionNode struct {
+	Species    string          `json:"species"`
+	Caught     bool            `json:"caught"`
+	Conditions []string        `json:"conditions"`
+	EvolvesTo  []evolutionNode `json:"evolves_to"`
+}
+
+func (r evolutionResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, r.Chain.label())
+	r.Chain.renderBranches(w, "")
+}
+
+func (n evolutionNode) label() string {
+	label := n.Species
+	if len(n.Conditions) > 0 {
+		label += " (" + strings.Join(n.Conditions, " or ") + ")"
+	}
+	if n.Caught {
+		label += " [caught]"
+	}
+	return label
+}
+
+// renderBranches draws the stages after n as a tree, indenting each level
+// below the branch it grows from.
+func (n evolutionNode) renderBranches(w io.Writer, indent string) {
+	for i, next := range n.EvolvesTo {
+		branch, below := "|- ", "|  "
+		if i == len(n.EvolvesTo)-1 {
+			branch, below = "`- ", "   "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, next.label())
+		next.renderBranches(w, indent+below)
+	}
+}
+
+type evolveResult struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Level int    `json:"level"`
+}
+
+func (r evolveResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "What? %s is evolving!\nCongratulations! Your %s evolved into %s!\n", r.From, r.From, r.To)
+}
+
+type pcResult struct {
+	Pokemon []pcInfo `json:"pokemon"`
+}
+
+type pcInfo struct {
+	ID       int    `json:"id"`
+	Pokemon  string `json:"pokemon"`
+	Nickname string `json:"nickname,omitempty"`
+	Level    int    `json:"level"`
+	Gender   string `json:"gender,omitempty"`
+	Shiny    bool   `json:"shiny,omitempty"`
+}
+
+func (r pcResult) renderText(w io.Writer) {
+	if len(r.Pokemon) == 0 {
+		fmt.Fprintln(w, "Your PC is empty. Use the 'catch' command to catch your first Pokemon!")
+		return
+	}
+	fmt.Fprintln(w, "Your PC:")
+	for _, info := range r.Pokemon {
+		name := info.Pokemon
+		if info.Nickname != "" {
+			name = fmt.Sprintf("%s (%s)", info.Nickname, info.Pokemon)
+		}
+		fmt.Fprintf(w, "  #%d %s, lv %d", info.ID, name, info.Level)
+		if info.Gender != "" {
+			fmt.Fprintf(w, ", %s", info.Gender)
+		}
+		if info.Shiny {
+			fmt.Fprint(w, ", shiny")
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+type searchResult struct {
+	Term    string            `json:"term"`
+	Matches []nameindex.Match `json:"matches"`
+}
+
+func (r searchResult) renderText(w io.Writer) {
+	if len(r.Matches) == 0 {
+		fmt.Fprintf(w, "Nothing matches %q\n", r.Term)
+		return
+	}
+	fmt.Fprintf(w, "Matches for %q:\n", r.Term)
+	for _, match := range r.Matches {
+		fmt.Fprintf(w, " - %s (%s)\n", match.Name, match.Kind)
+	}
+}
+
+type pokedexResult struct {
+	Pokemon []string `json:"pokemon"`
+}
+
+func (r pokedexResult) renderText(w io.Writer) {
+	if len(r.Pokemon) == 0 {
+		fmt.Fprintln(w, "You do not have any Pokemon in your Pokedex. Use the 'catch' command to catch your first one!")
+		return
+	}
+	fmt.Fprintln(w, "Your Pokedex:")
+	for _, name := range r.Pokemon {
+		fmt.Fprintf(w, "  - %s\n", name)
+	}
+}
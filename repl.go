@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errExit = errors.New("exit requested")
+var errUnknownCommand = errors.New("Unknown command")
+
+func getFirstWord(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0]
+}
+
+func displayOutput(word string) string {
+	if word == "" {
+		return "Please enter a command\n"
+	}
+	return fmt.Sprintf("Your command was: %s\n", word)
+}
+
+// startREPL runs the REPL over plain input such as a pipe. Interactive
+// terminals use startLineEditor instead.
+func startREPL(st *state, scanner *bufio.Scanner) {
+	runREPL(st, func() (string, bool) {
+		fmt.Fprint(st.out, "Pokedex > ")
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				fmt.Fprintf(st.out, "Error reading input %v\n", err)
+			}
+			return "", false
+		}
+		return scanner.Text(), true
+	})
+}
+
+// runREPL executes lines from readLine until it reports no more input or a
+// command asks to exit.
+func runREPL(st *state, readLine func() (string, bool)) {
+	for {
+		line, ok := readLine()
+		if !ok {
+			break
+		}
+		err := runCommand(st, line)
+		if errors.Is(err, errExit) {
+			return
+		}
+		if err != nil {
+			st.renderError(st.out, err)
+		}
+
+	}
+}
+
+// runCommand executes a single line of input. It returns errExit when the
+// line asked the Pokedex to close.
+func runCommand(st *state, line string) error {
+	userInput, tokens, err := processCommand(line)
+	if err != nil {
+		return err
+	}
+	command, exists := commands[userInput]
+	if !exists {
+		return unknownCommand(userInput)
+	}
+	args, err := parseArgs(command, tokens)
+	if err != nil {
+		return fmt.Errorf("Error executing %v command: %w", userInput, err)
+	}
+	res, err := command.callback(st, args)
+	if res != nil {
+		if renderErr := st.render(res); renderErr != nil {
+			return renderErr
+		}
+	}
+	if err != nil && !errors.Is(err, errExit) {
+		return fmt.Errorf("Error executing %v command: %w", userInput, err)
+	}
+	return err
+}
+
+// processCommand splits a line into the command name and the tokens that
+// follow it.
+func processCommand(userInput string) (string, []string, error) {
+	tokens, err := tokenize(userInput)
+	if err != nil {
+		return "", nil, err
+	}
+	firstWord := strings.ToLower(getFirstWord(tokens))
+	if len(tokens) < 2 {
+		return firstWord, []string{}, nil
+	}
+	return firstWord, tokens[1:], nil
+}
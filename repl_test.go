@@ -1,14 +1,20 @@
 package main
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
+
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
 )
 
-func TestCleanInput(t *testing.T) {
+func TestTokenize(t *testing.T) {
 	cases := []struct {
-		name     string
-		input    string
-		expected []string
+		name        string
+		input       string
+		expected    []string
+		expectedErr error
 	}{
 		{
 			name:     "empty spaces before, middle, and after",
@@ -18,12 +24,12 @@ func TestCleanInput(t *testing.T) {
 		{
 			name:     "first word capitalized second word all caps",
 			input:    "Hello WORLD",
-			expected: []string{"hello", "world"},
+			expected: []string{"Hello", "WORLD"},
 		},
 		{
 			name:     "empty spaces and capitilization",
 			input:    "   HELLO   world",
-			expected: []string{"hello", "world"},
+			expected: []string{"HELLO", "world"},
 		},
 		{
 			name:     "empty input",
@@ -35,10 +41,34 @@ func TestCleanInput(t *testing.T) {
 			input:    "   ",
 			expected: []string{},
 		},
+		{
+			name:     "quoted words keep spaces and case",
+			input:    `nickname 3 "Mr Sparky"`,
+			expected: []string{"nickname", "3", "Mr Sparky"},
+		},
+		{
+			name:     "single quotes and empty quotes",
+			input:    `set 'output' ""`,
+			expected: []string{"set", "output", ""},
+		},
+		{
+			name:     "flags are plain words",
+			input:    "catch Pikachu --ball=ULTRA",
+			expected: []string{"catch", "Pikachu", "--ball=ULTRA"},
+		},
+		{
+			name:        "unterminated quote",
+			input:       `explore "eterna`,
+			expected:    nil,
+			expectedErr: errUnterminatedQuote,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			actual := cleanInput(c.input)
+			actual, err := tokenize(c.input)
+			if err != c.expectedErr {
+				t.Errorf("Expected error: %v, Got: %v", c.expectedErr, err)
+			}
 			// Check the length of the actual slice against the expected slice
 			// if they don't match, use t.Errorf to print an error message
 			// and fail the test
@@ -59,6 +89,38 @@ func TestCleanInput(t *testing.T) {
 	}
 }
 
+func TestSplitCommands(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single command",
+			input:    "pokedex",
+			expected: []string{"pokedex"},
+		},
+		{
+			name:     "several commands",
+			input:    "goto pastoria-city-area; walk;catch",
+			expected: []string{"goto pastoria-city-area", " walk", "catch"},
+		},
+		{
+			name:     "semicolons inside quotes",
+			input:    `nickname 1 "a;b"; nickname 2 'c;d'`,
+			expected: []string{`nickname 1 "a;b"`, ` nickname 2 'c;d'`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := splitCommands(c.input)
+			if strings.Join(actual, "|") != strings.Join(c.expected, "|") {
+				t.Errorf("Expected: %q, Got: %q", c.expected, actual)
+			}
+		})
+	}
+}
+
 func TestGetFirstWord(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -121,63 +183,267 @@ func TestDisplayOutput(t *testing.T) {
 
 func TestProcessCommand(t *testing.T) {
 	cases := []struct {
-		name           string
-		input          string
-		expectedFirst  string
-		expectedSecond string
+		name          string
+		input         string
+		expectedFirst string
+		expectedArgs  []string
 	}{
 		{
-			name:           "one word input no caps and no spaces",
-			input:          "hello",
-			expectedFirst:  "hello",
-			expectedSecond: "",
+			name:          "one word input no caps and no spaces",
+			input:         "hello",
+			expectedFirst: "hello",
+			expectedArgs:  []string{},
 		},
 		{
-			name:           "one word input with caps",
-			input:          "HELLO",
-			expectedFirst:  "hello",
-			expectedSecond: "",
+			name:          "one word input with caps",
+			input:         "HELLO",
+			expectedFirst: "hello",
+			expectedArgs:  []string{},
 		},
 		{
-			name:           "Multi word input with caps",
-			input:          "This is a test command a user may submit",
-			expectedFirst:  "this",
-			expectedSecond: "is",
+			name:          "Multi word input with caps",
+			input:         "This is a test command a user may submit",
+			expectedFirst: "this",
+			expectedArgs:  []string{"is", "a", "test", "command", "a", "user", "may", "submit"},
 		},
 		{
-			name:           "Empty input",
-			input:          "",
-			expectedFirst:  "",
-			expectedSecond: "",
+			name:          "Empty input",
+			input:         "",
+			expectedFirst: "",
+			expectedArgs:  []string{},
 		},
 		{
-			name:           "Input all spaces",
-			input:          "     ",
-			expectedFirst:  "",
-			expectedSecond: "",
+			name:          "Input all spaces",
+			input:         "     ",
+			expectedFirst: "",
+			expectedArgs:  []string{},
 		},
 		{
-			name:           "Multi word all lower case",
-			input:          "explore foo",
-			expectedFirst:  "explore",
-			expectedSecond: "foo",
+			name:          "Multi word all lower case",
+			input:         "explore foo",
+			expectedFirst: "explore",
+			expectedArgs:  []string{"foo"},
 		},
 		{
-			name:           "Multi word with caps in second word",
-			input:          "explore FOO",
-			expectedFirst:  "explore",
-			expectedSecond: "foo",
+			name:          "Multi word with caps in second word",
+			input:         "Explore FOO",
+			expectedFirst: "explore",
+			expectedArgs:  []string{"FOO"},
 		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			actualFirst, actualSecond := processCommand(c.input)
+			actualFirst, actualArgs, err := processCommand(c.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if actualFirst != c.expectedFirst {
 				t.Errorf("Expected: %v, Actual: %v", c.expectedFirst, actualFirst)
 			}
-			if actualSecond != c.expectedSecond {
-				t.Errorf("Expected: %v, Actual: %v", c.expectedSecond, actualSecond)
+			if strings.Join(actualArgs, " ") != strings.Join(c.expectedArgs, " ") || len(actualArgs) != len(c.expectedArgs) {
+				t.Errorf("Expected: %v, Actual: %v", c.expectedArgs, actualArgs)
+			}
+		})
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	cases := []struct {
+		name      string
+		script    string
+		keepGoing bool
+		expected  int
+	}{
+		{
+			name:     "all commands succeed",
+			script:   "# list the pokedex\n\npokedex\n",
+			expected: 0,
+		},
+		{
+			name:     "unknown command fails",
+			script:   "pokedex\nbogus\npokedex\n",
+			expected: 1,
+		},
+		{
+			name:      "keep going still reports failure",
+			script:    "bogus\npokedex\n",
+			keepGoing: true,
+			expected:  1,
+		},
+		{
+			name:     "exit stops the script",
+			script:   "pokedex\nexit\nbogus\n",
+			expected: 0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			st := newState(pokeapi.NewClient(), io.Discard, io.Discard)
+			actual := runScript(st, strings.NewReader(c.script), c.keepGoing)
+			if actual != c.expected {
+				t.Errorf("Expected: %v, Got: %v", c.expected, actual)
 			}
 		})
 	}
 }
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		input    result
+		expected string
+	}{
+		{
+			name:     "catch as text",
+			format:   outputText,
+			input:    catchResult{Pokemon: "pikachu", Ball: "Poke Ball", Caught: false, Attempts: 1},
+			expected: "Throwing a Poke Ball at pikachu...pikachu escaped!\nAttempted catch: 1\n",
+		},
+		{
+			name:     "catch as json",
+			format:   outputJSON,
+			input:    catchResult{Pokemon: "pikachu", Ball: "Ultra Ball", Caught: true, Attempts: 0},
+			expected: `{"pokemon":"pikachu","ball":"Ultra Ball","caught":true,"attempts":0}` + "\n",
+		},
+		{
+			name:     "catch with an ultra ball",
+			format:   outputText,
+			input:    catchResult{Pokemon: "pikachu", Ball: "Ultra Ball", Caught: true},
+			expected: "Throwing an Ultra Ball at pikachu...pikachu was caught!\n",
+		},
+		{
+			name:     "empty pokedex as json",
+			format:   outputJSON,
+			input:    pokedexResult{Pokemon: []string{}},
+			expected: `{"pokemon":[]}` + "\n",
+		},
+		{
+			name:     "pokemon from an old save",
+			format:   outputText,
+			input:    inspectResult{Name: "pikachu", Level: 5, XP: -1, Gender: "unknown", CaughtIn: "unknown"},
+			expected: "Name: pikachu\nHeight: 0\nWeight: 0\nStats:\nTypes:\nLevel: 5\nXP: unknown\nGender: unknown\nCaught: at an unknown time in unknown\n",
+		},
+		{
+			name:     "wild pokemon after finding a ball",
+			format:   outputText,
+			input:    wildResult{Pokemon: "pidgey", Level: 3, Area: "route-1", Found: "Great Ball"},
+			expected: "You found a Great Ball!\nA wild pidgey (lv 3) appeared in route-1!\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			st := newState(pokeapi.NewClient(), &buf, io.Discard)
+			if err := st.setOutputFormat(c.format); err != nil {
+				t.Fatal(err)
+			}
+			if err := st.render(c.input); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != c.expected {
+				t.Errorf("Expected: %q, Got: %q", c.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	command := cliCommand{
+		name:  "catch",
+		args:  []argSpec{{name: "pokemon_name"}, {name: "count", optional: true}},
+		flags: []flagSpec{{name: "ball", value: "ball"}, {name: "quiet"}},
+	}
+	cases := []struct {
+		name          string
+		input         []string
+		expectedArgs  []string
+		expectedFlags map[string]string
+		expectedErr   string
+	}{
+		{
+			name:          "positional and flag with value",
+			input:         []string{"pikachu", "--ball", "ultra"},
+			expectedArgs:  []string{"pikachu"},
+			expectedFlags: map[string]string{"ball": "ultra"},
+		},
+		{
+			name:          "equals form and boolean flag",
+			input:         []string{"--quiet", "pikachu", "2", "--ball=great"},
+			expectedArgs:  []string{"pikachu", "2"},
+			expectedFlags: map[string]string{"ball": "great", "quiet": ""},
+		},
+		{
+			name:          "names are lower-cased, quoted or not",
+			input:         []string{"Pikachu", "--BALL", "Ultra"},
+			expectedArgs:  []string{"pikachu"},
+			expectedFlags: map[string]string{"ball": "ultra"},
+		},
+		{
+			name:          "double dash ends flags",
+			input:         []string{"--", "--ball"},
+			expectedArgs:  []string{"--ball"},
+			expectedFlags: map[string]string{},
+		},
+		{
+			name:        "missing required argument",
+			input:       []string{"--quiet"},
+			expectedErr: "missing <pokemon_name>, usage: catch <pokemon_name> [<count>] [--ball <ball>] [--quiet]",
+		},
+		{
+			name:        "too many arguments",
+			input:       []string{"pikachu", "2", "3"},
+			expectedErr: `unexpected argument "3", usage: catch <pokemon_name> [<count>] [--ball <ball>] [--quiet]`,
+		},
+		{
+			name:        "unknown flag",
+			input:       []string{"pikachu", "--net"},
+			expectedErr: "unknown flag --net, usage: catch <pokemon_name> [<count>] [--ball <ball>] [--quiet]",
+		},
+		{
+			name:        "flag missing its value",
+			input:       []string{"pikachu", "--ball"},
+			expectedErr: "--ball needs a value, usage: catch <pokemon_name> [<count>] [--ball <ball>] [--quiet]",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := parseArgs(command, c.input)
+			if c.expectedErr != "" {
+				if err == nil || err.Error() != c.expectedErr {
+					t.Errorf("Expected error: %v, Got: %v", c.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(actual.positional, " ") != strings.Join(c.expectedArgs, " ") {
+				t.Errorf("Expected: %v, Got: %v", c.expectedArgs, actual.positional)
+			}
+			if len(actual.flags) != len(c.expectedFlags) {
+				t.Errorf("Expected: %v, Got: %v", c.expectedFlags, actual.flags)
+			}
+			for name, value := range c.expectedFlags {
+				if actual.flag(name) != value || !actual.hasFlag(name) {
+					t.Errorf("Expected --%s=%v, Got: %v", name, value, actual.flags)
+				}
+			}
+		})
+	}
+}
+
+func TestParseArgsKeepsCase(t *testing.T) {
+	command := cliCommand{name: "nickname", args: []argSpec{{name: "pokemon"}, {name: "nickname", keepCase: true}}}
+	tokens, err := tokenize(`nickname Pikachu "Mr Sparky"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := parseArgs(command, tokens[1:])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual.arg(0) != "pikachu" || actual.arg(1) != "Mr Sparky" {
+		t.Errorf("Expected: [pikachu Mr Sparky], Got: %v", actual.positional)
+	}
+}
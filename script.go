@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runScriptFile implements `pokedexcli run [--keep-going] <script>`.
+func runScriptFile(st *state, args []string, keepGoing bool) int {
+	runFlags := flag.NewFlagSet("run", flag.ContinueOnError)
+	runFlags.BoolVar(&keepGoing, "keep-going", keepGoing, "keep running the script after a command fails")
+	if err := runFlags.Parse(args); err != nil {
+		return 2
+	}
+	if runFlags.NArg() != 1 {
+		fmt.Fprintln(st.errOut, "usage: pokedexcli run [--keep-going] <script>")
+		return 2
+	}
+
+	file, err := os.Open(runFlags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(st.errOut, "failed to open script: %v\n", err)
+		return 2
+	}
+	defer file.Close()
+	return runScript(st, file, keepGoing)
+}
+
+// runScript executes one command per line without prompting. Blank lines
+// and lines starting with '#' are skipped. It stops at the first failing
+// command unless keepGoing is set, and returns the process exit code.
+func runScript(st *state, r io.Reader, keepGoing bool) int {
+	status := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		err := runCommand(st, line)
+		if errors.Is(err, errExit) {
+			return status
+		}
+		if err != nil {
+			st.renderError(st.errOut, err)
+			status = 1
+			if !keepGoing {
+				return status
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(st.errOut, "Error reading script %v\n", err)
+		return 2
+	}
+	return status
+}
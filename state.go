@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/thmastin/pokedexcli/internal/nameindex"
+	"github.com/thmastin/pokedexcli/internal/pokeapi"
+	"github.com/thmastin/pokedexcli/internal/savefile"
+	"github.com/thmastin/pokedexcli/internal/trainer"
+)
+
+// state is everything a command can read or change. Commands write their
+// output to out and never touch the process directly, so a whole session
+// can be driven from tests.
+type state struct {
+	out          io.Writer
+	errOut       io.Writer
+	outputFormat string
+	client       *pokeapi.Client
+	index        *nameindex.Index
+	listings     map[string]*paginator
+	// prefetchEncounters also warms the encounters of every listed area.
+	prefetchEncounters bool
+	knownRegions       map[string]bool
+	knownLocations     map[string]bool
+	knownAreas         map[string]bool
+	lastEncounter      []string
+	trainer            trainer.Trainer
+	pc                 trainer.PC
+	rng                *rand.Rand
+	savePath           string
+}
+
+func newState(client *pokeapi.Client, out, errOut io.Writer) *state {
+	return &state{
+		out:            out,
+		errOut:         errOut,
+		outputFormat:   outputText,
+		client:         client,
+		index:          nameindex.New(client.ResourceNames, cacheInterval),
+		listings:       newPaginators(),
+		knownRegions:   make(map[string]bool),
+		knownLocations: make(map[string]bool),
+		knownAreas:     make(map[string]bool),
+		lastEncounter:  []string{},
+		trainer:        trainer.Trainer{Bag: trainer.NewBag()},
+		pc:             trainer.PC{Pokemon: []trainer.Entry{}, NextID: 1},
+		rng:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// knownNames is where names shown by a listing are remembered for
+// completion, or nil if that listing's names are not remembered.
+func (st *state) knownNames(key string) map[string]bool {
+	switch key {
+	case "regions":
+		return st.knownRegions
+	case "locations":
+		return st.knownLocations
+	case "areas":
+		return st.knownAreas
+	default:
+		return nil
+	}
+}
+
+func (st *state) warnf(format string, args ...any) {
+	fmt.Fprintf(st.errOut, "Warning: "+format+"\n", args...)
+}
+
+// loadSave restores the pokedex from the default save file and remembers
+// the path so later catches are written back to it.
+func (st *state) loadSave() {
+	path, err := savefile.DefaultPath()
+	if err != nil {
+		st.warnf("your pokedex will not be saved: %v", err)
+		return
+	}
+	st.savePath = path
+
+	data, err := savefile.Load(st.savePath)
+	if errors.Is(err, savefile.ErrUnsupportedVersion) {
+		// The save is from a newer build. Leave it alone so it is still
+		// there after upgrading again.
+		st.warnf("%v, your pokedex will not be saved", err)
+		st.savePath = ""
+		return
+	}
+	if errors.Is(err, savefile.ErrCorrupt) {
+		backup, moveErr := savefile.Quarantine(st.savePath)
+		if moveErr != nil {
+			st.warnf("%v and could not be moved aside (%v), your pokedex will not be saved", err, moveErr)
+			st.savePath = ""
+			return
+		}
+		st.warnf("%v. It was moved to %s and a new pokedex was started.", err, backup)
+		return
+	}
+	if err != nil {
+		st.warnf("%v, your pokedex will not be saved", err)
+		st.savePath = ""
+		return
+	}
+	st.pc = data.PC
+	st.trainer.Location = data.Location
+	st.trainer.Bag = data.Bag
+}
+
+// loadArea fetches the encounters of the trainer's location if they are
+// not loaded yet, as happens after restoring a save.
+func (st *state) loadArea() error {
+	if st.trainer.Location == "" || st.trainer.Area.Name == st.trainer.Location {
+		return nil
+	}
+	encounter, err := st.client.Encounter(st.trainer.Location)
+	if err != nil {
+		return err
+	}
+	st.trainer.Goto(trainer.NewArea(st.trainer.Location, encounter))
+	return nil
+}
+
+func (st *state) writeSave() error {
+	if st.savePath == "" {
+		return nil
+	}
+	data := savefile.New()
+	data.PC = st.pc
+	data.Location = st.trainer.Location
+	data.Bag = st.trainer.Bag
+	return savefile.Save(st.savePath, data)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/thmastin/pokedexcli/internal/fuzzy"
+	"github.com/thmastin/pokedexcli/internal/nameindex"
+)
+
+// didYouMean adds the candidate closest to word to err, if any candidate
+// is a plausible typo of it.
+func didYouMean(err error, word string, candidates []string) error {
+	match, ok := fuzzy.Closest(word, candidates)
+	if !ok || match == word {
+		return err
+	}
+	return fmt.Errorf("%w, did you mean %q?", err, match)
+}
+
+func unknownCommand(name string) error {
+	return didYouMean(errUnknownCommand, name, commandNames(nil))
+}
+
+// areaCandidates lists every location area we know of: those seen in this
+// session plus the name index when it can be loaded.
+func (st *state) areaCandidates() []string {
+	return st.withIndex(knownAreaNames(st), nameindex.Area)
+}
+
+func (st *state) pokemonCandidates() []string {
+	return st.withIndex(st.lastEncounter, nameindex.Pokemon)
+}
+
+func (st *state) locationCandidates() []string {
+	return st.withIndex(knownLocationNames(st), nameindex.Location)
+}
+
+func (st *state) regionCandidates() []string {
+	return st.withIndex(knownRegionNames(st), nameindex.Region)
+}
+
+// withIndex adds every indexed name of kind to the names seen this session,
+// listing each name once.
+func (st *state) withIndex(seen []string, kind nameindex.Kind) []string {
+	names := append([]string{}, seen...)
+	if all, err := st.index.Names(kind); err == nil {
+		names = append(names, all...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}